@@ -34,6 +34,11 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+
+// @securityDefinitions.apikey ApiKey
+// @in header
+// @name X-API-Key
+// @description Personal API key, accepted on message endpoints.
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -46,7 +51,6 @@ func main() {
 
 	// Initialize logger
 	logger := telemetry.NewLogger(cfg)
-	defer logger.Sync()
 
 	// Setup OpenTelemetry
 	tp, err := telemetry.SetupTracing(ctx, cfg)
@@ -82,7 +86,11 @@ func main() {
 	defer redisClient.Close()
 
 	// Create application
-	application := app.New(ctx, cfg, db, redisClient, logger)
+	application, err := app.New(ctx, cfg, db, redisClient, logger)
+	if err != nil {
+		logger.Error("Failed to create application", "error", err)
+		os.Exit(1)
+	}
 
 	// Start the application
 	g, gCtx := errgroup.WithContext(ctx)
@@ -97,6 +105,21 @@ func main() {
 		return application.StartGRPCServer(gCtx)
 	})
 
+	// Start trash purger
+	g.Go(func() error {
+		return application.StartTrashPurger(gCtx)
+	})
+
+	// Start unread counter reconciler
+	g.Go(func() error {
+		return application.StartUnreadReconciler(gCtx)
+	})
+
+	// Start message event stream
+	g.Go(func() error {
+		return application.StartMessageEvents(gCtx)
+	})
+
 	// Handle shutdown signals
 	g.Go(func() error {
 		signalChan := make(chan os.Signal, 1)
@@ -2,15 +2,24 @@ package app
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"log/slog"
 
 	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/internal/core/apikey"
 	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/channel"
+	"github.com/ivmello/go-api-template/internal/core/conversation"
 	"github.com/ivmello/go-api-template/internal/core/message"
+	"github.com/ivmello/go-api-template/internal/core/reaction"
+	"github.com/ivmello/go-api-template/internal/core/unread"
 	"github.com/ivmello/go-api-template/internal/infrastructure/http_client"
+	"github.com/ivmello/go-api-template/internal/infrastructure/mail"
+	"github.com/ivmello/go-api-template/internal/infrastructure/oidc"
+	"github.com/ivmello/go-api-template/internal/infrastructure/ratelimit"
 )
 
 // Application holds all dependencies of the application
@@ -22,44 +31,162 @@ type Application struct {
 	httpClient  *http_client.Client
 
 	// Services
-	authService    *auth.Service
-	messageService *message.Service
+	authService         *auth.Service
+	oidcService         *auth.OIDCService
+	apiKeyService       *apikey.Service
+	channelService      *channel.Service
+	conversationService *conversation.Service
+	messageService      *message.Service
+	reactionService     *reaction.Service
+	unreadService       *unread.Service
+
+	// Event streams
+	messageEvents *message.EventStream
+
+	// Rate limiting
+	rateLimiter     *ratelimit.Limiter
+	rateLimitPolicy *ratelimit.Policy
 }
 
 // New creates a new Application with all dependencies
-func New(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Client, logger *slog.Logger) *Application {
+func New(ctx context.Context, cfg *config.Config, db *pgxpool.Pool, redisClient *redis.Client, logger *slog.Logger) (*Application, error) {
 	// Initialize HTTP client for external APIs
 	httpClient := http_client.NewClient(cfg.ExternalAPI.Timeout)
 
 	// Initialize repositories
 	authRepo := auth.NewRepository(db)
-	messageRepo := message.NewRepository(db)
+	apiKeyRepo := apikey.NewRepository(db)
+	channelRepo := channel.NewRepository(db)
+	conversationRepo := conversation.NewRepository(db)
+	var messageRepo message.Store = message.NewRepository(db)
+	if cfg.Messages.CacheTTL > 0 {
+		messageRepo = message.NewCachedRepository(messageRepo, redisClient, cfg.Messages.CacheTTL, logger)
+	}
+	reactionRepo := reaction.NewRepository(db)
+
+	// Fail at startup rather than on every search
+	if err := message.CheckSearchLanguage(ctx, db, cfg.Search.Language); err != nil {
+		return nil, err
+	}
+	unreadRepo := unread.NewRepository(db)
+
+	// Initialize token signing keys, revocation store and login throttle
+	keyManager, err := auth.LoadKeyManager(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+	revocationStore := auth.NewRevocationStore(redisClient)
+	loginThrottle := auth.NewLoginThrottle(redisClient, cfg.Auth)
+
+	// Initialize rate limiter
+	rateLimiter, err := ratelimit.NewLimiter(redisClient, cfg.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize mail sender
+	mailer, err := mail.NewSender(cfg.Mail, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize services
-	authService := auth.NewService(authRepo, cfg.JWT)
-	messageService := message.NewService(messageRepo)
+	authService := auth.NewService(authRepo, revocationStore, loginThrottle, keyManager, mailer, logger, cfg.JWT, cfg.Auth)
+	apiKeyService := apikey.NewService(apiKeyRepo, authService)
+	oidcService := auth.NewOIDCService(authService, authRepo, auth.NewOIDCStateStore(redisClient, cfg.OIDC.StateTTL), newOIDCProviders(cfg))
+	unreadService := unread.NewService(unreadRepo, unread.NewStore(redisClient, cfg.Unread.CounterTTL), logger)
+	channelService := channel.NewService(channelRepo, authService, unreadService)
+	conversationService := conversation.NewService(conversationRepo, authService, unreadService)
+	messageEvents := message.NewEventStream(redisClient, cfg.Messages.EventRetention, logger)
+	messageService := message.NewService(messageRepo, channelService, unreadService, messageEvents, cfg.Messages, cfg.Search)
+	reactionService := reaction.NewService(reactionRepo, messageService)
 
 	return &Application{
-		config:         cfg,
-		db:             db,
-		redisClient:    redisClient,
-		logger:         logger,
-		httpClient:     httpClient,
-		authService:    authService,
-		messageService: messageService,
-	}
+		config:              cfg,
+		db:                  db,
+		redisClient:         redisClient,
+		logger:              logger,
+		httpClient:          httpClient,
+		authService:         authService,
+		oidcService:         oidcService,
+		apiKeyService:       apiKeyService,
+		channelService:      channelService,
+		conversationService: conversationService,
+		messageService:      messageService,
+		reactionService:     reactionService,
+		unreadService:       unreadService,
+		messageEvents:       messageEvents,
+		rateLimiter:         rateLimiter,
+		rateLimitPolicy:     newRateLimitPolicy(cfg.RateLimit),
+	}, nil
 }
 
 // Services returns all application services
 func (a *Application) Services() struct {
-	Auth    *auth.Service
-	Message *message.Service
+	Auth         *auth.Service
+	OIDC         *auth.OIDCService
+	APIKey       *apikey.Service
+	Channel      *channel.Service
+	Conversation *conversation.Service
+	Message      *message.Service
+	Reaction     *reaction.Service
+	Unread       *unread.Service
 } {
 	return struct {
-		Auth    *auth.Service
-		Message *message.Service
+		Auth         *auth.Service
+		OIDC         *auth.OIDCService
+		APIKey       *apikey.Service
+		Channel      *channel.Service
+		Conversation *conversation.Service
+		Message      *message.Service
+		Reaction     *reaction.Service
+		Unread       *unread.Service
 	}{
-		Auth:    a.authService,
-		Message: a.messageService,
+		Auth:         a.authService,
+		OIDC:         a.oidcService,
+		APIKey:       a.apiKeyService,
+		Channel:      a.channelService,
+		Conversation: a.conversationService,
+		Message:      a.messageService,
+		Reaction:     a.reactionService,
+		Unread:       a.unreadService,
+	}
+}
+
+// requireVerifiedEmailForMessages reports whether writing messages is gated on a verified email
+func (a *Application) requireVerifiedEmailForMessages() bool {
+	return a.config.Auth.RequireVerifiedEmailFor == auth.RequireVerifiedEmailForMessages
+}
+
+// authRateLimitRoutes are the login and registration routes, which get the
+// stricter auth rate limit
+var authRateLimitRoutes = []string{
+	"POST /api/v1/auth/login",
+	"POST /api/v1/auth/register",
+	"/auth.AuthService/Login",
+	"/auth.AuthService/Register",
+}
+
+// newRateLimitPolicy creates the rate limit policy. Routes configured
+// explicitly take precedence over the auth limit.
+func newRateLimitPolicy(cfg config.RateLimitConfig) *ratelimit.Policy {
+	routes := make(map[string]config.RateLimit, len(authRateLimitRoutes)+len(cfg.Routes))
+	for _, route := range authRateLimitRoutes {
+		routes[route] = cfg.Auth
+	}
+	for route, limit := range cfg.Routes {
+		routes[route] = limit
+	}
+	return ratelimit.NewPolicy(cfg.Default, routes)
+}
+
+// newOIDCProviders creates a relying party for each configured OIDC provider
+func newOIDCProviders(cfg *config.Config) []*oidc.Provider {
+	client := &http.Client{Timeout: cfg.ExternalAPI.Timeout}
+
+	providers := make([]*oidc.Provider, len(cfg.OIDC.Providers))
+	for i, providerConfig := range cfg.OIDC.Providers {
+		providers[i] = oidc.NewProvider(providerConfig, client)
 	}
+	return providers
 }
\ No newline at end of file
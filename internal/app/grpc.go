@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net"
 
-	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/ivmello/go-api-template/internal/handlers/grpc/apikey"
 	"github.com/ivmello/go-api-template/internal/handlers/grpc/auth"
+	"github.com/ivmello/go-api-template/internal/handlers/grpc/channel"
+	"github.com/ivmello/go-api-template/internal/handlers/grpc/conversation"
 	"github.com/ivmello/go-api-template/internal/handlers/grpc/message"
+	"github.com/ivmello/go-api-template/internal/handlers/grpc/unread"
 	"github.com/ivmello/go-api-template/internal/middleware"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
@@ -24,19 +27,20 @@ func (a *Application) StartGRPCServer(ctx context.Context) error {
 
 	// Create gRPC server with middleware
 	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(
-			grpcMiddleware.ChainUnaryServer(
-				middleware.GRPCLogger(a.logger),
-				otelgrpc.UnaryServerInterceptor(),
-				middleware.GRPCAuth(),
-			),
+		grpc.ChainUnaryInterceptor(
+			middleware.GRPCLogger(a.logger),
+			otelgrpc.UnaryServerInterceptor(),
+			middleware.GRPCAuth(a.Services().Auth, a.Services().APIKey),
+			middleware.GRPCRateLimit(a.rateLimiter, a.rateLimitPolicy, a.logger),
+			middleware.GRPCAuthorize(),
+			middleware.GRPCRequireVerifiedEmail(a.requireVerifiedEmailForMessages()),
 		),
-		grpc.StreamInterceptor(
-			grpcMiddleware.ChainStreamServer(
-				middleware.GRPCStreamLogger(a.logger),
-				otelgrpc.StreamServerInterceptor(),
-				middleware.GRPCStreamAuth(),
-			),
+		grpc.ChainStreamInterceptor(
+			middleware.GRPCStreamLogger(a.logger),
+			otelgrpc.StreamServerInterceptor(),
+			middleware.GRPCStreamAuth(a.Services().Auth, a.Services().APIKey),
+			middleware.GRPCStreamRateLimit(a.rateLimiter, a.rateLimitPolicy, a.logger),
+			middleware.GRPCStreamAuthorize(),
 		),
 	)
 
@@ -68,7 +72,23 @@ func (a *Application) registerGRPCServices(server *grpc.Server) {
 	authServer := auth.NewServer(a.Services().Auth)
 	auth.RegisterAuthServiceServer(server, authServer)
 
+	// Register API key service
+	apiKeyServer := apikey.NewServer(a.Services().APIKey)
+	apikey.RegisterApiKeyServiceServer(server, apiKeyServer)
+
+	// Register Channel service
+	channelServer := channel.NewServer(a.Services().Channel)
+	channel.RegisterChannelServiceServer(server, channelServer)
+
+	// Register Conversation service
+	conversationServer := conversation.NewServer(a.Services().Conversation)
+	conversation.RegisterConversationServiceServer(server, conversationServer)
+
 	// Register Message service
-	messageServer := message.NewServer(a.Services().Message)
+	messageServer := message.NewServer(a.Services().Message, a.Services().Reaction)
 	message.RegisterMessageServiceServer(server, messageServer)
+
+	// Register Unread service
+	unreadServer := unread.NewServer(a.Services().Unread)
+	unread.RegisterUnreadServiceServer(server, unreadServer)
 }
\ No newline at end of file
@@ -8,10 +8,15 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/handlers/http/apikey"
 	"github.com/ivmello/go-api-template/internal/handlers/http/auth"
+	"github.com/ivmello/go-api-template/internal/handlers/http/channel"
+	"github.com/ivmello/go-api-template/internal/handlers/http/conversation"
 	"github.com/ivmello/go-api-template/internal/handlers/http/healthcheck"
 	"github.com/ivmello/go-api-template/internal/handlers/http/message"
+	"github.com/ivmello/go-api-template/internal/handlers/http/unread"
 	"github.com/ivmello/go-api-template/internal/middleware"
+	pkgAuth "github.com/ivmello/go-api-template/pkg/auth"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
@@ -26,6 +31,9 @@ func (a *Application) StartHTTPServer(ctx context.Context) error {
 
 	// Create router with middleware
 	router := gin.New()
+	if err := router.SetTrustedProxies(a.config.App.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	router.Use(
 		gin.Recovery(),
 		middleware.LoggerMiddleware(a.logger),
@@ -33,14 +41,20 @@ func (a *Application) StartHTTPServer(ctx context.Context) error {
 		middleware.CORSMiddleware(),
 	)
 
+	// Event streams keep their connection busy, so Shutdown would wait for
+	// them until it times out. They are ended as soon as shutdown starts.
+	streamsCtx, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+
 	// Register routes
-	a.registerHTTPRoutes(router)
+	a.registerHTTPRoutes(streamsCtx, router)
 
 	// Create server
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", a.config.App.Port),
 		Handler: router,
 	}
+	server.RegisterOnShutdown(stopStreams)
 
 	// Start server in a goroutine
 	go func() {
@@ -68,8 +82,9 @@ func (a *Application) StartHTTPServer(ctx context.Context) error {
 	return nil
 }
 
-// registerHTTPRoutes registers all HTTP routes
-func (a *Application) registerHTTPRoutes(router *gin.Engine) {
+// registerHTTPRoutes registers all HTTP routes. Streaming routes end when
+// streamsCtx is canceled.
+func (a *Application) registerHTTPRoutes(streamsCtx context.Context, router *gin.Engine) {
 	// Root route for health check
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -78,6 +93,20 @@ func (a *Application) registerHTTPRoutes(router *gin.Engine) {
 		})
 	})
 
+	// Authentication middleware; API keys are only accepted on message and
+	// channel routes
+	authMiddleware := middleware.AuthMiddleware(a.Services().Auth, nil)
+	apiKeyMiddleware := middleware.AuthMiddleware(a.Services().Auth, a.Services().APIKey)
+
+	// Rate limiting; it follows authentication on protected routes so callers
+	// are limited by identity rather than IP
+	rateLimit := middleware.RateLimit(a.rateLimiter, a.rateLimitPolicy, a.logger)
+
+	// Public token verification keys
+	authHandler := auth.NewHandler(a.Services().Auth)
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+	oidcHandler := auth.NewOIDCHandler(a.Services().OIDC)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -91,23 +120,99 @@ func (a *Application) registerHTTPRoutes(router *gin.Engine) {
 		}
 
 		// Auth routes
-		authHandler := auth.NewHandler(a.Services().Auth)
 		authGroup := v1.Group("/auth")
 		{
-			authGroup.POST("/register", authHandler.Register)
-			authGroup.POST("/login", authHandler.Login)
-			authGroup.GET("/me", middleware.AuthMiddleware(), authHandler.Me)
+			authGroup.POST("/register", rateLimit, authHandler.Register)
+			authGroup.POST("/login", rateLimit, authHandler.Login)
+			authGroup.POST("/refresh", rateLimit, authHandler.Refresh)
+			authGroup.POST("/password/forgot", rateLimit, authHandler.ForgotPassword)
+			authGroup.POST("/password/reset", rateLimit, authHandler.ResetPassword)
+			authGroup.GET("/verify", rateLimit, authHandler.VerifyEmail)
+			authGroup.POST("/verify/resend", rateLimit, authHandler.ResendVerification)
+			authGroup.POST("/2fa/verify", rateLimit, authHandler.VerifyMFA)
+			authGroup.GET("/oidc/:provider/login", rateLimit, oidcHandler.Login)
+			authGroup.GET("/oidc/:provider/callback", rateLimit, oidcHandler.Callback)
+			authGroup.POST("/2fa/enroll", authMiddleware, rateLimit, authHandler.EnrollTOTP)
+			authGroup.POST("/2fa/confirm", authMiddleware, rateLimit, authHandler.ConfirmTOTP)
+			authGroup.POST("/2fa/disable", authMiddleware, rateLimit, authHandler.DisableTOTP)
+			authGroup.POST("/logout", authMiddleware, rateLimit, authHandler.Logout)
+			authGroup.POST("/logout-all", authMiddleware, rateLimit, authHandler.LogoutAll)
+			authGroup.GET("/me", authMiddleware, rateLimit, authHandler.Me)
 		}
 
 		// Message routes
-		messageHandler := message.NewHandler(a.Services().Message)
+		messageHandler := message.NewHandler(a.Services().Message, a.Services().Reaction)
+		canRead := middleware.RequirePermission(pkgAuth.PermMessagesRead)
+		canWrite := middleware.RequirePermission(pkgAuth.PermMessagesWrite)
+		// Every write requires a verified email when REQUIRE_VERIFIED_EMAIL_FOR
+		// is "messages"
+		verified := middleware.RequireVerifiedEmail(a.requireVerifiedEmailForMessages())
+		stream := middleware.CancelOnShutdown(streamsCtx)
+		messageGateway := message.NewGateway(a.Services().Message, a.requireVerifiedEmailForMessages())
 		messageGroup := v1.Group("/messages")
 		{
-			messageGroup.GET("", messageHandler.GetAll)                                // Public
-			messageGroup.GET("/:id", middleware.AuthMiddleware(), messageHandler.Get)  // Protected
-			messageGroup.POST("", middleware.AuthMiddleware(), messageHandler.Create)  // Protected
-			messageGroup.PUT("/:id", middleware.AuthMiddleware(), messageHandler.Update) // Protected
-			messageGroup.DELETE("/:id", middleware.AuthMiddleware(), messageHandler.Delete) // Protected
+			messageGroup.GET("", apiKeyMiddleware, rateLimit, canRead, messageHandler.List)                                              // Protected
+			messageGroup.GET("/search", apiKeyMiddleware, rateLimit, canRead, messageHandler.Search)                                     // Protected
+			messageGroup.GET("/trash", apiKeyMiddleware, rateLimit, canRead, messageHandler.Trash)                                       // Protected
+			messageGroup.GET("/events", apiKeyMiddleware, rateLimit, canRead, stream, messageHandler.Events)                             // Protected
+			messageGroup.GET("/ws", middleware.WebSocketBearerToken(), authMiddleware, rateLimit, canRead, stream, messageGateway.Serve) // Protected
+			messageGroup.GET("/:id", apiKeyMiddleware, rateLimit, canRead, messageHandler.Get)                                           // Protected
+			messageGroup.POST("", apiKeyMiddleware, rateLimit, canWrite, verified, messageHandler.Create)                                // Protected
+			messageGroup.PUT("/:id", apiKeyMiddleware, rateLimit, canWrite, verified, messageHandler.Update)                             // Protected
+			messageGroup.DELETE("/:id", apiKeyMiddleware, rateLimit, canWrite, verified, messageHandler.Delete)                          // Protected
+			messageGroup.POST("/:id/restore", apiKeyMiddleware, rateLimit, canWrite, verified, messageHandler.Restore)                   // Protected
+			messageGroup.GET("/:id/revisions", apiKeyMiddleware, rateLimit, canRead, messageHandler.Revisions)                           // Protected
+			messageGroup.GET("/:id/revisions/diff", apiKeyMiddleware, rateLimit, canRead, messageHandler.DiffRevisions)                  // Protected
+			messageGroup.GET("/:id/thread", apiKeyMiddleware, rateLimit, canRead, messageHandler.Thread)                                 // Protected
+			messageGroup.PUT("/:id/reactions/:emoji", apiKeyMiddleware, rateLimit, canWrite, verified, messageHandler.AddReaction)       // Protected
+			messageGroup.DELETE("/:id/reactions/:emoji", apiKeyMiddleware, rateLimit, canWrite, verified, messageHandler.RemoveReaction) // Protected
+		}
+
+		// Channel routes
+		channelHandler := channel.NewHandler(a.Services().Channel)
+		channelGroup := v1.Group("/channels", apiKeyMiddleware, rateLimit)
+		{
+			channelGroup.POST("", canWrite, verified, channelHandler.Create)
+			channelGroup.GET("", canRead, channelHandler.List)
+			channelGroup.GET("/:id", canRead, channelHandler.Get)
+			channelGroup.POST("/:id/join", canWrite, verified, channelHandler.Join)
+			channelGroup.POST("/:id/leave", canWrite, verified, channelHandler.Leave)
+			channelGroup.GET("/:id/members", canRead, channelHandler.Members)
+			channelGroup.POST("/:id/members", canWrite, verified, channelHandler.Invite)
+			channelGroup.POST("/:id/read", canWrite, verified, channelHandler.MarkRead)
+		}
+
+		// Conversation routes
+		conversationHandler := conversation.NewHandler(a.Services().Conversation)
+		conversationGroup := v1.Group("/conversations", authMiddleware, rateLimit)
+		{
+			conversationGroup.POST("", canWrite, verified, conversationHandler.Create)
+			conversationGroup.GET("", canRead, conversationHandler.List)
+			conversationGroup.GET("/:id", canRead, conversationHandler.Get)
+			conversationGroup.POST("/:id/participants", canWrite, verified, conversationHandler.AddParticipants)
+			conversationGroup.DELETE("/:id/participants/:user_id", canWrite, verified, conversationHandler.RemoveParticipant)
+			conversationGroup.GET("/:id/messages", canRead, conversationHandler.Messages)
+			conversationGroup.POST("/:id/messages", canWrite, verified, conversationHandler.SendMessage)
+			conversationGroup.POST("/:id/read", canWrite, verified, conversationHandler.MarkRead)
+		}
+
+		// Unread routes
+		unreadHandler := unread.NewHandler(a.Services().Unread)
+		v1.GET("/unread", authMiddleware, rateLimit, unreadHandler.Get)
+
+		// API key routes
+		apiKeyHandler := apikey.NewHandler(a.Services().APIKey)
+		apiKeyGroup := v1.Group("/api-keys", authMiddleware, rateLimit)
+		{
+			apiKeyGroup.POST("", apiKeyHandler.Create)
+			apiKeyGroup.GET("", apiKeyHandler.List)
+			apiKeyGroup.DELETE("/:id", apiKeyHandler.Revoke)
+		}
+
+		// Admin routes
+		adminGroup := v1.Group("/admin", authMiddleware, rateLimit, middleware.RequireRole(pkgAuth.RoleAdmin))
+		{
+			adminGroup.PUT("/users/:id/roles", authHandler.SetRoles)
 		}
 	}
 
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// StartTrashPurger periodically deletes messages that have been in the trash
+// for longer than the retention period
+func (a *Application) StartTrashPurger(ctx context.Context) error {
+	a.logger.Info("Starting trash purger",
+		"interval", a.config.Messages.TrashPurgeInterval,
+		"retention", a.config.Messages.TrashRetention,
+	)
+
+	ticker := time.NewTicker(a.config.Messages.TrashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		a.purgeTrash(ctx)
+
+		select {
+		case <-ctx.Done():
+			a.logger.Info("Trash purger stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeTrash runs a single purge, logging the outcome
+func (a *Application) purgeTrash(ctx context.Context) {
+	purged, err := a.Services().Message.PurgeTrash(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Error("Failed to purge trashed messages", "error", err, "purged", purged)
+		}
+		return
+	}
+
+	if purged > 0 {
+		a.logger.Info("Purged trashed messages", "count", purged)
+	}
+}
+
+// StartMessageEvents delivers message events published by any instance to
+// the watchers connected to this one
+func (a *Application) StartMessageEvents(ctx context.Context) error {
+	a.logger.Info("Starting message event stream", "retention", a.config.Messages.EventRetention)
+
+	err := a.messageEvents.Run(ctx)
+
+	a.logger.Info("Message event stream stopped")
+	return err
+}
+
+// StartUnreadReconciler periodically repairs unread counters in Redis that
+// drifted from the read pointers in Postgres
+func (a *Application) StartUnreadReconciler(ctx context.Context) error {
+	a.logger.Info("Starting unread counter reconciler", "interval", a.config.Unread.ReconcileInterval)
+
+	ticker := time.NewTicker(a.config.Unread.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.logger.Info("Unread counter reconciler stopped")
+			return nil
+		case <-ticker.C:
+			a.reconcileUnread(ctx)
+		}
+	}
+}
+
+// reconcileUnread runs a single reconciliation, logging the outcome. It is
+// skipped when another instance reconciled within the interval.
+func (a *Application) reconcileUnread(ctx context.Context) {
+	claimed, err := a.Services().Unread.ClaimReconcile(ctx, a.config.Unread.ReconcileInterval)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Error("Failed to claim unread counter reconciliation", "error", err)
+		}
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	checked, repaired, err := a.Services().Unread.Reconcile(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Error("Failed to reconcile unread counters", "error", err, "checked", checked, "repaired", repaired)
+		}
+		return
+	}
+
+	if repaired > 0 {
+		a.logger.Info("Repaired unread counters", "checked", checked, "repaired", repaired)
+	}
+}
\ No newline at end of file
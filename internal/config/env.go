@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,6 +16,13 @@ type Config struct {
 	Database   DatabaseConfig
 	Redis      RedisConfig
 	JWT        JWTConfig
+	Auth       AuthConfig
+	Mail       MailConfig
+	OIDC       OIDCConfig
+	Messages   MessagesConfig
+	Search     SearchConfig
+	Unread     UnreadConfig
+	RateLimit  RateLimitConfig
 	Telemetry  TelemetryConfig
 	ExternalAPI ExternalAPIConfig
 }
@@ -25,6 +33,11 @@ type AppConfig struct {
 	Environment string
 	Port        int
 	GRPCPort    int
+
+	// TrustedProxies lists the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header is trusted for the client IP. When empty,
+	// the client IP is the address of the connection.
+	TrustedProxies []string
 }
 
 // DatabaseConfig holds database connection configuration
@@ -48,8 +61,87 @@ type RedisConfig struct {
 
 // JWTConfig holds JWT authentication configuration
 type JWTConfig struct {
-	Secret        string
-	ExpirationHours int
+	Secret          string
+	PrivateKeyFile  string
+	RetiredKeyFiles []string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// AuthConfig holds account management configuration
+type AuthConfig struct {
+	PasswordResetTTL        time.Duration
+	PasswordResetURL        string
+	EmailVerificationTTL    time.Duration
+	EmailVerificationURL    string
+	RequireVerifiedEmailFor string // "login", "messages" or empty
+	TOTPIssuer              string
+	TOTPEncryptionKey       string
+	MFAChallengeTTL         time.Duration
+	LoginMaxFailures        int
+	LoginMaxFailuresPerIP   int
+	LoginBackoffBase        time.Duration
+	LoginBackoffMax         time.Duration
+	LoginLockoutDuration    time.Duration
+	LoginFailureWindow      time.Duration
+}
+
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	Timeout      time.Duration
+}
+
+// OIDCConfig holds external OpenID Connect login configuration
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	StateTTL  time.Duration
+}
+
+// OIDCProviderConfig holds the client registration for one OIDC provider
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// MessagesConfig holds message retention and event configuration
+type MessagesConfig struct {
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	// EventRetention is the approximate number of recent message events kept
+	// for watchers resuming after a disconnect
+	EventRetention int
+
+	// CacheTTL is how long messages and listing pages are cached in Redis.
+	// Zero disables the cache.
+	CacheTTL time.Duration
+}
+
+// SearchConfig holds message full-text search configuration
+type SearchConfig struct {
+	// Language is the Postgres text search configuration used to parse
+	// queries and build snippets. It must match the configuration the
+	// messages.search_vector column and its index are built with, english in
+	// migration 000011; changing it needs a migration rebuilding the column.
+	Language string
+}
+
+// UnreadConfig holds unread message counter configuration
+type UnreadConfig struct {
+	// CounterTTL is how long a user's counters stay in Redis before they are
+	// rebuilt from Postgres on the next read
+	CounterTTL        time.Duration
+	ReconcileInterval time.Duration
 }
 
 // TelemetryConfig holds telemetry configuration
@@ -58,6 +150,25 @@ type TelemetryConfig struct {
 	ExporterEndpoint string
 }
 
+// RateLimitConfig holds request rate limiting configuration
+type RateLimitConfig struct {
+	Algorithm string // "sliding_window" or "token_bucket"
+	Default   RateLimit
+	Auth      RateLimit // Login and registration
+
+	// Routes overrides the limit of HTTP routes, keyed by method and path
+	// pattern such as "POST /api/v1/messages", and of gRPC methods, keyed by
+	// full method name
+	Routes map[string]RateLimit
+}
+
+// RateLimit allows a number of requests per period. A limit of zero
+// requests disables rate limiting.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
 // ExternalAPIConfig holds configuration for external API calls
 type ExternalAPIConfig struct {
 	Timeout time.Duration
@@ -68,12 +179,13 @@ func Load() (*Config, error) {
 	// Load .env file if it exists
 	godotenv.Load()
 
-	return &Config{
+	cfg := &Config{
 		App: AppConfig{
-			Name:        getEnv("APP_NAME", "go-api-template"),
-			Environment: getEnv("ENVIRONMENT", "development"),
-			Port:        getEnvAsInt("PORT", 8080),
-			GRPCPort:    getEnvAsInt("GRPC_PORT", 9090),
+			Name:           getEnv("APP_NAME", "go-api-template"),
+			Environment:    getEnv("ENVIRONMENT", "development"),
+			Port:           getEnvAsInt("PORT", 8080),
+			GRPCPort:       getEnvAsInt("GRPC_PORT", 9090),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Host:           getEnv("DB_HOST", "localhost"),
@@ -91,8 +203,59 @@ func Load() (*Config, error) {
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "default-secret-key-for-development-only"),
-			ExpirationHours: getEnvAsInt("JWT_EXPIRATION_HOURS", 24),
+			Secret:          getEnv("JWT_SECRET", "default-secret-key-for-development-only"),
+			PrivateKeyFile:  getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			RetiredKeyFiles: getEnvAsSlice("JWT_RETIRED_KEY_FILES", nil),
+			AccessTokenTTL:  getEnvAsDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvAsDuration("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		},
+		Auth: AuthConfig{
+			PasswordResetTTL:        getEnvAsDuration("PASSWORD_RESET_TOKEN_TTL", time.Hour),
+			PasswordResetURL:        getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+			EmailVerificationTTL:    getEnvAsDuration("EMAIL_VERIFICATION_TOKEN_TTL", 24*time.Hour),
+			EmailVerificationURL:    getEnv("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/v1/auth/verify"),
+			RequireVerifiedEmailFor: getEnv("REQUIRE_VERIFIED_EMAIL_FOR", ""),
+			TOTPIssuer:              getEnv("TOTP_ISSUER", "go-api-template"),
+			TOTPEncryptionKey:       getEnv("TOTP_ENCRYPTION_KEY", ""),
+			MFAChallengeTTL:         getEnvAsDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+			LoginMaxFailures:        getEnvAsInt("LOGIN_MAX_FAILURES", 5),
+			LoginMaxFailuresPerIP:   getEnvAsInt("LOGIN_MAX_FAILURES_PER_IP", 20),
+			LoginBackoffBase:        getEnvAsDuration("LOGIN_BACKOFF_BASE", time.Second),
+			LoginBackoffMax:         getEnvAsDuration("LOGIN_BACKOFF_MAX", time.Minute),
+			LoginLockoutDuration:    getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			LoginFailureWindow:      getEnvAsDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@example.com"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 1025),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			Timeout:      getEnvAsDuration("SMTP_TIMEOUT", 10*time.Second),
+		},
+		OIDC: OIDCConfig{
+			Providers: loadOIDCProviders(),
+			StateTTL:  getEnvAsDuration("OIDC_STATE_TTL", 10*time.Minute),
+		},
+		Messages: MessagesConfig{
+			TrashRetention:     getEnvAsDuration("MESSAGE_TRASH_RETENTION", 30*24*time.Hour),
+			TrashPurgeInterval: getEnvAsDuration("MESSAGE_TRASH_PURGE_INTERVAL", time.Hour),
+			EventRetention:     getEnvAsInt("MESSAGE_EVENT_RETENTION", 10000),
+			CacheTTL:           getEnvAsDuration("MESSAGE_CACHE_TTL", time.Minute),
+		},
+		Search: SearchConfig{
+			Language: getEnv("SEARCH_LANGUAGE", "english"),
+		},
+		Unread: UnreadConfig{
+			CounterTTL:        getEnvAsDuration("UNREAD_COUNTER_TTL", 7*24*time.Hour),
+			ReconcileInterval: getEnvAsDuration("UNREAD_RECONCILE_INTERVAL", 15*time.Minute),
+		},
+		RateLimit: RateLimitConfig{
+			Algorithm: getEnv("RATE_LIMIT_ALGORITHM", "sliding_window"),
+			Default:   getEnvAsRateLimit("RATE_LIMIT_DEFAULT", RateLimit{Requests: 300, Period: time.Minute}),
+			Auth:      getEnvAsRateLimit("RATE_LIMIT_AUTH", RateLimit{Requests: 10, Period: time.Minute}),
+			Routes:    getEnvAsRateLimits("RATE_LIMIT_ROUTES"),
 		},
 		Telemetry: TelemetryConfig{
 			ServiceName:      getEnv("OTEL_SERVICE_NAME", "go-api-template"),
@@ -101,7 +264,43 @@ func Load() (*Config, error) {
 		ExternalAPI: ExternalAPIConfig{
 			Timeout: getEnvAsDuration("EXTERNAL_API_TIMEOUT", 5*time.Second),
 		},
-	}, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate reports the first setting that the application can't run with
+func (c *Config) Validate() error {
+	switch c.Auth.RequireVerifiedEmailFor {
+	case "", "login", "messages":
+	default:
+		return fmt.Errorf("REQUIRE_VERIFIED_EMAIL_FOR must be login, messages or empty, got %q", c.Auth.RequireVerifiedEmailFor)
+	}
+	if c.Auth.TOTPEncryptionKey == "" {
+		return fmt.Errorf("TOTP_ENCRYPTION_KEY is required")
+	}
+	switch c.RateLimit.Algorithm {
+	case "sliding_window", "token_bucket":
+	default:
+		return fmt.Errorf("RATE_LIMIT_ALGORITHM must be sliding_window or token_bucket, got %q", c.RateLimit.Algorithm)
+	}
+	if c.Messages.TrashRetention <= 0 {
+		return fmt.Errorf("MESSAGE_TRASH_RETENTION must be positive, got %s", c.Messages.TrashRetention)
+	}
+	if c.Messages.TrashPurgeInterval <= 0 {
+		return fmt.Errorf("MESSAGE_TRASH_PURGE_INTERVAL must be positive, got %s", c.Messages.TrashPurgeInterval)
+	}
+	if c.Messages.EventRetention <= 0 {
+		return fmt.Errorf("MESSAGE_EVENT_RETENTION must be positive, got %d", c.Messages.EventRetention)
+	}
+	if c.Unread.ReconcileInterval <= 0 {
+		return fmt.Errorf("UNREAD_RECONCILE_INTERVAL must be positive, got %s", c.Unread.ReconcileInterval)
+	}
+
+	return nil
 }
 
 // Helper functions to get environment variables
@@ -129,6 +328,81 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvAsRateLimit(key string, defaultValue RateLimit) RateLimit {
+	if limit, ok := parseRateLimit(getEnv(key, "")); ok {
+		return limit
+	}
+	return defaultValue
+}
+
+// getEnvAsRateLimits reads a comma-separated list of limits in the form
+// key=requests/period. Malformed entries are skipped.
+func getEnvAsRateLimits(key string) map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	for _, entry := range getEnvAsSlice(key, nil) {
+		i := strings.LastIndex(entry, "=")
+		if i < 0 {
+			continue
+		}
+		if limit, ok := parseRateLimit(entry[i+1:]); ok {
+			limits[strings.TrimSpace(entry[:i])] = limit
+		}
+	}
+	return limits
+}
+
+// parseRateLimit parses a limit in the form requests/period, such as 100/1m
+func parseRateLimit(value string) (RateLimit, bool) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimit{}, false
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return RateLimit{}, false
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, false
+	}
+
+	return RateLimit{Requests: n, Period: d}, true
+}
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS. Each
+// provider is configured with variables prefixed OIDC_<NAME>_.
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range getEnvAsSlice("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER_URL", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       getEnvAsSlice(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+		})
+	}
+	return providers
+}
+
 // GetDSN returns the database connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  RateLimit
+		ok    bool
+	}{
+		{"100/1m", RateLimit{Requests: 100, Period: time.Minute}, true},
+		{" 5/30s ", RateLimit{Requests: 5, Period: 30 * time.Second}, true},
+		{"0/1h", RateLimit{Requests: 0, Period: time.Hour}, true},
+		{"", RateLimit{}, false},
+		{"100", RateLimit{}, false},
+		{"-1/1m", RateLimit{}, false},
+		{"ten/1m", RateLimit{}, false},
+		{"100/minute", RateLimit{}, false},
+		{"100/0s", RateLimit{}, false},
+		{"100/-1m", RateLimit{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRateLimit(tt.value)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseRateLimit(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGetEnvAsRateLimits(t *testing.T) {
+	t.Setenv("TEST_RATE_LIMITS", "POST /api/v1/messages=60/1m, /message.MessageService/CreateMessage=10/1s,broken,GET /x=bad")
+
+	limits := getEnvAsRateLimits("TEST_RATE_LIMITS")
+
+	want := map[string]RateLimit{
+		"POST /api/v1/messages":                 {Requests: 60, Period: time.Minute},
+		"/message.MessageService/CreateMessage": {Requests: 10, Period: time.Second},
+	}
+	if len(limits) != len(want) {
+		t.Fatalf("limits = %v, want %v", limits, want)
+	}
+	for route, limit := range want {
+		if limits[route] != limit {
+			t.Errorf("limit of %q = %v, want %v", route, limits[route], limit)
+		}
+	}
+}
+
+// validConfig returns a configuration that passes Validate
+func validConfig() *Config {
+	return &Config{
+		Auth:      AuthConfig{TOTPEncryptionKey: "key"},
+		RateLimit: RateLimitConfig{Algorithm: "sliding_window"},
+		Messages: MessagesConfig{
+			TrashRetention:     time.Hour,
+			TrashPurgeInterval: time.Hour,
+			EventRetention:     100,
+		},
+		Unread: UnreadConfig{ReconcileInterval: time.Hour},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*Config)
+	}{
+		{"verified email scope", func(c *Config) { c.Auth.RequireVerifiedEmailFor = "everything" }},
+		{"missing TOTP key", func(c *Config) { c.Auth.TOTPEncryptionKey = "" }},
+		{"rate limit algorithm", func(c *Config) { c.RateLimit.Algorithm = "leaky_bucket" }},
+		{"trash retention", func(c *Config) { c.Messages.TrashRetention = 0 }},
+		{"trash purge interval", func(c *Config) { c.Messages.TrashPurgeInterval = -time.Minute }},
+		{"event retention", func(c *Config) { c.Messages.EventRetention = 0 }},
+		{"reconcile interval", func(c *Config) { c.Unread.ReconcileInterval = 0 }},
+	}
+
+	for _, tt := range tests {
+		cfg := validConfig()
+		tt.change(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: Validate accepted an invalid setting", tt.name)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,76 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/ivmello/go-api-template/pkg/auth"
+)
+
+const (
+	// keyType starts every API key so they are easy to recognize in leaks
+	keyType = "ak"
+
+	// prefixBytes is the size of the public lookup prefix
+	prefixBytes = 6
+)
+
+// APIKey represents a personal API key. Only the hash of the key is stored;
+// the prefix is kept in plaintext to look the key up.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewAPIKey creates a new API key and returns it together with its plaintext
+// value, formatted as ak_<prefix>_<secret>
+func NewAPIKey(userID, name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error) {
+	b := make([]byte, prefixBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	prefix := hex.EncodeToString(b)
+
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext := keyType + "_" + prefix + "_" + secret
+
+	return &APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   auth.HashToken(plaintext),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, plaintext, nil
+}
+
+// ParsePrefix extracts the lookup prefix from a plaintext key
+func ParsePrefix(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != keyType || len(parts[1]) != 2*prefixBytes || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
\ No newline at end of file
@@ -0,0 +1,153 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// Repository provides access to API key storage
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new API key repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Create inserts a new API key into the database
+func (r *Repository) Create(ctx context.Context, key *APIKey) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.ExpiresAt,
+		key.CreatedAt,
+	).Scan(&key.ID)
+}
+
+// GetByPrefix retrieves an API key by its lookup prefix
+func (r *Repository) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	key := &APIKey{}
+
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE prefix = $1
+	`
+
+	err := r.db.QueryRow(ctx, query, prefix).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// ListByUser retrieves the API keys of a user, newest first
+func (r *Repository) ListByUser(ctx context.Context, userID string) ([]*APIKey, error) {
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key := &APIKey{}
+		err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.Prefix,
+			&key.KeyHash,
+			&key.Scopes,
+			&key.ExpiresAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes an active API key owned by the user
+func (r *Repository) Revoke(ctx context.Context, id, userID string) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed records that a key was used. Writes are skipped while the
+// stored time is less than a minute old to keep hot keys from hammering the row.
+func (r *Repository) TouchLastUsed(ctx context.Context, id string) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
\ No newline at end of file
@@ -0,0 +1,123 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	pkgAuth "github.com/ivmello/go-api-template/pkg/auth"
+)
+
+var (
+	ErrInvalidScope  = errors.New("invalid API key scope")
+	ErrInvalidExpiry = errors.New("API key expiry must be in the future")
+	ErrInvalidAPIKey = errors.New("invalid, revoked or expired API key")
+)
+
+// UserGetter loads the owner of an API key
+type UserGetter interface {
+	GetUserByID(ctx context.Context, id string) (*auth.User, error)
+}
+
+// Service provides API key operations
+type Service struct {
+	repo  *Repository
+	users UserGetter
+}
+
+// NewService creates a new API key service
+func NewService(repo *Repository, users UserGetter) *Service {
+	return &Service{
+		repo:  repo,
+		users: users,
+	}
+}
+
+// Create issues a new API key for the user. The plaintext key is returned
+// only here and cannot be retrieved again.
+func (s *Service) Create(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error) {
+	// Validate scopes and expiry
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !pkgAuth.IsAPIKeyScope(pkgAuth.Permission(scope)) {
+			return nil, "", ErrInvalidScope
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	// Create key
+	key, plaintext, err := NewAPIKey(userID, name, scopes, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Save key to database
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, plaintext, nil
+}
+
+// List retrieves the API keys of a user
+func (s *Service) List(ctx context.Context, userID string) ([]*APIKey, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Revoke revokes one of the user's API keys
+func (s *Service) Revoke(ctx context.Context, id, userID string) error {
+	return s.repo.Revoke(ctx, id, userID)
+}
+
+// ValidateAPIKey authenticates a plaintext API key and returns claims for its
+// owner, restricted to the key's scopes
+func (s *Service) ValidateAPIKey(ctx context.Context, plaintext string) (*pkgAuth.Claims, error) {
+	// Look up key by prefix
+	prefix, ok := ParsePrefix(plaintext)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	// Check secret and status
+	hash := pkgAuth.HashToken(plaintext)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(key.KeyHash)) != 1 || !key.IsActive() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Load owner for current roles
+	user, err := s.users.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	// Track usage
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		return nil, err
+	}
+
+	claims := &pkgAuth.Claims{
+		UserID:        user.ID,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		Roles:         user.Roles,
+		Scopes:        key.Scopes,
+	}
+	claims.ID = key.ID
+	return claims, nil
+}
\ No newline at end of file
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/infrastructure/mail"
+)
+
+// passwordResetEmail builds the email carrying a password reset link
+func passwordResetEmail(user *User, resetURL, token string, ttl time.Duration) mail.Message {
+	link := resetURL + "?token=" + url.QueryEscape(token)
+
+	return mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(`Hi %s,
+
+We received a request to reset your password. Use the link below to choose a new one:
+
+%s
+
+The link expires in %s and can only be used once. If you did not request a reset, you can ignore this email.
+`, user.Name, link, ttl),
+	}
+}
+
+// verificationEmail builds the email carrying an email verification link
+func verificationEmail(user *User, verifyURL, token string, ttl time.Duration) mail.Message {
+	link := verifyURL + "?token=" + url.QueryEscape(token)
+
+	return mail.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Please confirm your email address by opening the link below:
+
+%s
+
+The link expires in %s.
+`, user.Name, link, ttl),
+	}
+}
\ No newline at end of file
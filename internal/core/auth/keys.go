@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/pkg/auth"
+)
+
+// hmacKeyID identifies the shared-secret key used when no private key is configured
+const hmacKeyID = "hs256"
+
+// LoadKeyManager builds the token key manager from configuration. Tokens are
+// signed with the configured RSA or Ed25519 private key; without one they fall
+// back to HS256 with the shared secret. Retired keys only verify tokens.
+func LoadKeyManager(cfg config.JWTConfig) (*auth.KeyManager, error) {
+	// Fall back to the shared secret
+	if cfg.PrivateKeyFile == "" {
+		return auth.NewKeyManager(auth.NewHMACKey(hmacKeyID, cfg.Secret))
+	}
+
+	// Load active signing key
+	signingKey, err := auth.LoadPrivateKeyFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load retired verification keys
+	retiredKeys := make([]*auth.Key, 0, len(cfg.RetiredKeyFiles))
+	for _, path := range cfg.RetiredKeyFiles {
+		key, err := auth.LoadPublicKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		retiredKeys = append(retiredKeys, key)
+	}
+
+	return auth.NewKeyManager(signingKey, retiredKeys...)
+}
@@ -1,26 +1,37 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
 	"time"
 
 	"github.com/ivmello/go-api-template/pkg/auth"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// recoveryCodeBytes is the amount of entropy in a recovery code
+const recoveryCodeBytes = 10
+
 // User represents a user in the system
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	Name         string    `json:"name"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	PasswordHash    string     `json:"-"`
+	Name            string     `json:"name"`
+	Roles           []string   `json:"roles"`
+	TOTPSecret      string     `json:"-"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at,omitempty"`
+	TOTPLastStep    int64      `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // NewUser creates a new user with a hashed password
 func NewUser(email, password, name string) (*User, error) {
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(password)
 	if err != nil {
 		return nil, err
 	}
@@ -28,20 +39,213 @@ func NewUser(email, password, name string) (*User, error) {
 	now := time.Now()
 	return &User{
 		Email:        email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Name:         name,
+		Roles:        []string{auth.RoleUser},
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}, nil
 }
 
+// HashPassword hashes a plaintext password with bcrypt
+func HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
 // ComparePassword checks if the provided password matches the stored hash
 func (u *User) ComparePassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
 	return err == nil
 }
 
+// IsEmailVerified reports whether the user has verified their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasTOTP reports whether the user has confirmed two-factor authentication
+func (u *User) HasTOTP() bool {
+	return u.TOTPEnabledAt != nil
+}
+
 // GenerateToken creates a new JWT token for the user
-func (u *User) GenerateToken(expirationHours int) (string, error) {
-	return auth.GenerateToken(u.ID, u.Email, expirationHours)
+func (u *User) GenerateToken(keys *auth.KeyManager, ttl time.Duration) (string, error) {
+	return keys.GenerateToken(auth.Claims{
+		UserID:        u.ID,
+		Email:         u.Email,
+		EmailVerified: u.IsEmailVerified(),
+		Roles:         u.Roles,
+	}, ttl)
+}
+
+// RefreshToken represents an opaque refresh token stored server-side.
+// Tokens issued from the same login share a FamilyID so that the whole
+// chain can be revoked when reuse of a rotated token is detected.
+type RefreshToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	FamilyID   string     `json:"family_id"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *string    `json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewRefreshToken creates a new refresh token and returns it together with
+// its plaintext value. Only the hash of the plaintext is ever persisted.
+func NewRefreshToken(userID, familyID string, ttl time.Duration) (*RefreshToken, string, error) {
+	plaintext, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(plaintext),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, plaintext, nil
+}
+
+// TokenPair holds an access token and the refresh token used to renew it
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// MFAChallenge is issued instead of a token pair when the password step of a
+// login succeeds for a user with two-factor authentication
+type MFAChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// LoginResult is the outcome of a password login. Exactly one of Tokens and
+// Challenge is set.
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge *MFAChallenge
+}
+
+// TOTPEnrollment holds a pending TOTP secret and the URI to import it into an
+// authenticator app
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// NewRecoveryCodes creates two-factor recovery codes and returns them together
+// with their hashes. Only the hashes are persisted.
+func NewRecoveryCodes(count int) ([]string, []string, error) {
+	codes := make([]string, count)
+	hashes := make([]string, count)
+
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		// Format as xxxx-xxxx-xxxx-xxxx for readability
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode hashes a recovery code, ignoring case, dashes and spaces
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return auth.HashToken(normalized)
+}
+
+// PasswordResetToken represents a single-use password reset token
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewPasswordResetToken creates a new reset token and returns it together
+// with its plaintext value
+func NewPasswordResetToken(userID string, ttl time.Duration) (*PasswordResetToken, string, error) {
+	plaintext, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &PasswordResetToken{
+		UserID:    userID,
+		TokenHash: auth.HashToken(plaintext),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, plaintext, nil
+}
+
+// EmailVerificationToken represents a single-use email verification token
+type EmailVerificationToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewEmailVerificationToken creates a new verification token and returns it
+// together with its plaintext value
+func NewEmailVerificationToken(userID string, ttl time.Duration) (*EmailVerificationToken, string, error) {
+	plaintext, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &EmailVerificationToken{
+		UserID:    userID,
+		TokenHash: auth.HashToken(plaintext),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, plaintext, nil
+}
+
+// UserIdentity links a user to their account at an external OIDC provider
+type UserIdentity struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewExternalUser creates a user that signs in through an external provider.
+// It has no password until one is set with a password reset.
+func NewExternalUser(email, name string, emailVerified bool) *User {
+	now := time.Now()
+	user := &User{
+		Email:     email,
+		Name:      name,
+		Roles:     []string{auth.RoleUser},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if emailVerified {
+		user.EmailVerifiedAt = &now
+	}
+	return user
 }
\ No newline at end of file
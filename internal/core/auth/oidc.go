@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/infrastructure/oidc"
+)
+
+var (
+	ErrUnknownProvider   = errors.New("unknown identity provider")
+	ErrInvalidOIDCState  = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed   = errors.New("identity provider login failed")
+	ErrOIDCEmailRequired = errors.New("identity provider did not return an email address")
+	ErrOIDCAccountExists = errors.New("an account with this email already exists, sign in with your password")
+)
+
+// OIDCService signs users in through external OpenID Connect providers and
+// issues the application's own tokens
+type OIDCService struct {
+	auth      *Service
+	repo      *Repository
+	states    *OIDCStateStore
+	providers map[string]*oidc.Provider
+}
+
+// NewOIDCService creates a new OIDC login service
+func NewOIDCService(authService *Service, repo *Repository, states *OIDCStateStore, providers []*oidc.Provider) *OIDCService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &OIDCService{
+		auth:      authService,
+		repo:      repo,
+		states:    states,
+		providers: byName,
+	}
+}
+
+// OIDCLogin is a started OIDC login
+type OIDCLogin struct {
+	// URL is the provider URL to redirect the user to
+	URL string
+
+	// State must be bound to the user's browser, such as in a cookie, and
+	// passed back to CompleteLogin with the callback
+	State string
+}
+
+// StartLogin begins an authorization code flow with PKCE
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (*OIDCLogin, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	// Generate per-request secrets
+	state, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remember the request until the provider redirects back
+	err = s.states.Save(ctx, state, &OIDCState{
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCLogin{URL: authURL, State: state}, nil
+}
+
+// CompleteLogin handles the provider callback. browserState is the state
+// bound to the browser making the callback, so a login started by someone
+// else can't be completed in it. The identity is matched to a linked user,
+// then to an existing user with the same verified email, and otherwise a new
+// user is created. Users with two-factor authentication get a challenge to
+// complete with VerifyMFA.
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, state, browserState, code string) (*LoginResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	// Check state; it must have been issued to this browser for this provider
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+	pending, err := s.states.Consume(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if pending.Provider != providerName {
+		return nil, ErrInvalidOIDCState
+	}
+
+	// Redeem the code and validate the ID token
+	tokens, err := provider.Exchange(ctx, code, pending.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, pending.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	user, err := s.findOrCreateUser(ctx, providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check email verification
+	if s.auth.cfg.RequireVerifiedEmailFor == RequireVerifiedEmailForLogin && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Require the second factor
+	if user.HasTOTP() {
+		challenge, err := s.auth.newMFAChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge}, nil
+	}
+
+	session, err := s.auth.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: session}, nil
+}
+
+// findOrCreateUser returns the user for a provider identity, linking or
+// creating an account on first sign in
+func (s *OIDCService) findOrCreateUser(ctx context.Context, providerName string, claims *oidc.IDClaims) (*User, error) {
+	// Already linked
+	user, err := s.repo.GetByIdentity(ctx, providerName, claims.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	identity := &UserIdentity{
+		Provider:  providerName,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	}
+
+	// Link to an existing account only when both sides proved ownership of
+	// the email, so nobody can take over an account by registering its address
+	user, err = s.repo.GetByEmail(ctx, claims.Email)
+	if err == nil {
+		if !claims.EmailVerified || !user.IsEmailVerified() {
+			return nil, ErrOIDCAccountExists
+		}
+		identity.UserID = user.ID
+		if err := s.repo.CreateIdentity(ctx, identity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+
+	// First sign in, create the account
+	user = NewExternalUser(claims.Email, claims.Name, claims.EmailVerified)
+	if err := s.repo.CreateWithIdentity(ctx, user, identity); err != nil {
+		if errors.Is(err, ErrEmailAlreadyExists) {
+			return nil, ErrOIDCAccountExists
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
\ No newline at end of file
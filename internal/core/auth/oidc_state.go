@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const oidcStateKeyPrefix = "auth:oidc:state:"
+
+// OIDCState is what the callback of an OIDC login needs to know about the
+// authorization request that started it
+type OIDCState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+}
+
+// OIDCStateStore keeps pending OIDC logins in Redis, keyed by the state parameter
+type OIDCStateStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewOIDCStateStore creates a new OIDC state store
+func NewOIDCStateStore(client *redis.Client, ttl time.Duration) *OIDCStateStore {
+	return &OIDCStateStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Save stores a pending login until the state expires
+func (s *OIDCStateStore) Save(ctx context.Context, state string, pending *OIDCState) error {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, oidcStateKeyPrefix+state, data, s.ttl).Err()
+}
+
+// Consume returns and deletes a pending login, so each state is used once.
+// Unknown or expired states return ErrInvalidOIDCState.
+func (s *OIDCStateStore) Consume(ctx context.Context, state string) (*OIDCState, error) {
+	data, err := s.client.GetDel(ctx, oidcStateKeyPrefix+state).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+
+	pending := &OIDCState{}
+	if err := json.Unmarshal(data, pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
\ No newline at end of file
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/internal/infrastructure/oidc"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCompleteLoginRequiresBrowserState(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	states := NewOIDCStateStore(client, time.Minute)
+	provider := oidc.NewProvider(config.OIDCProviderConfig{Name: "test"}, nil)
+	service := NewOIDCService(nil, nil, states, []*oidc.Provider{provider})
+
+	ctx := context.Background()
+	if err := states.Save(ctx, "victim-state", &OIDCState{Provider: "test"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	for _, browserState := range []string{"", "attacker-state"} {
+		_, err := service.CompleteLogin(ctx, "test", "victim-state", browserState, "code")
+		if !errors.Is(err, ErrInvalidOIDCState) {
+			t.Fatalf("CompleteLogin with browser state %q = %v, want ErrInvalidOIDCState", browserState, err)
+		}
+	}
+
+	// Rejected callbacks leave the login to the browser that started it
+	if _, err := states.Consume(ctx, "victim-state"); err != nil {
+		t.Fatalf("Consume after rejected callbacks: %v", err)
+	}
+}
\ No newline at end of file
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,6 +12,16 @@ import (
 var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrEmailAlreadyExists = errors.New("email already exists")
+
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenExpired  = errors.New("refresh token has expired")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected")
+
+	ErrResetTokenNotFound        = errors.New("password reset token not found")
+	ErrVerificationTokenNotFound = errors.New("email verification token not found")
+
+	ErrTOTPStepUsed         = errors.New("TOTP code has already been used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
 )
 
 // Repository provides access to the user storage
@@ -44,8 +55,8 @@ func (r *Repository) Create(ctx context.Context, user *User) error {
 
 	// Insert user
 	query := `
-		INSERT INTO users (email, password_hash, name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (email, password_hash, name, roles, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id
 	`
 	
@@ -53,6 +64,7 @@ func (r *Repository) Create(ctx context.Context, user *User) error {
 		user.Email,
 		user.PasswordHash,
 		user.Name,
+		user.Roles,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -63,7 +75,8 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*User, error) {
 	user := &User{}
 	
 	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at
+		SELECT id, email, email_verified_at, password_hash, name, roles,
+			COALESCE(totp_secret, ''), totp_enabled_at, totp_last_step, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -71,8 +84,13 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*User, error) {
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
+		&user.EmailVerifiedAt,
 		&user.PasswordHash,
 		&user.Name,
+		&user.Roles,
+		&user.TOTPSecret,
+		&user.TOTPEnabledAt,
+		&user.TOTPLastStep,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -92,7 +110,8 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 	user := &User{}
 	
 	query := `
-		SELECT id, email, password_hash, name, created_at, updated_at
+		SELECT id, email, email_verified_at, password_hash, name, roles,
+			COALESCE(totp_secret, ''), totp_enabled_at, totp_last_step, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -100,8 +119,13 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 	err := r.db.QueryRow(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
+		&user.EmailVerifiedAt,
 		&user.PasswordHash,
 		&user.Name,
+		&user.Roles,
+		&user.TOTPSecret,
+		&user.TOTPEnabledAt,
+		&user.TOTPLastStep,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -114,4 +138,548 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 	}
 	
 	return user, nil
+}
+
+// UpdateRoles replaces the roles of a user and returns the updated user in
+// the same statement, so the result reflects exactly this change
+func (r *Repository) UpdateRoles(ctx context.Context, id string, roles []string) (*User, error) {
+	user := &User{}
+
+	query := `
+		UPDATE users
+		SET roles = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING id, email, email_verified_at, password_hash, name, roles,
+			COALESCE(totp_secret, ''), totp_enabled_at, totp_last_step, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, roles, id).Scan(
+		&user.ID,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.PasswordHash,
+		&user.Name,
+		&user.Roles,
+		&user.TOTPSecret,
+		&user.TOTPEnabledAt,
+		&user.TOTPLastStep,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateRefreshToken stores a new refresh token. A new token family is
+// started when the token has no FamilyID.
+func (r *Repository) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, COALESCE(NULLIF($2, '')::uuid, gen_random_uuid()), $3, $4, $5)
+		RETURNING id, family_id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID, &token.FamilyID)
+}
+
+// RotateRefreshToken consumes the refresh token with the given hash and stores
+// its replacement in the same family, all within a single transaction.
+// Presenting a token that was already consumed or revoked revokes its whole
+// family and returns ErrRefreshTokenReused.
+func (r *Repository) RotateRefreshToken(ctx context.Context, tokenHash string, replacement *RefreshToken) (*RefreshToken, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the current token so concurrent refreshes cannot both succeed
+	current := &RefreshToken{}
+	err = tx.QueryRow(ctx, `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(
+		&current.ID,
+		&current.UserID,
+		&current.FamilyID,
+		&current.TokenHash,
+		&current.ExpiresAt,
+		&current.RevokedAt,
+		&current.ReplacedBy,
+		&current.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+
+	// Reuse of a revoked token: revoke every token in the family
+	if current.RevokedAt != nil {
+		_, err = tx.Exec(ctx,
+			"UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL",
+			current.FamilyID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return current, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, ErrRefreshTokenExpired
+	}
+
+	// Insert the replacement in the same family
+	replacement.UserID = current.UserID
+	replacement.FamilyID = current.FamilyID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`,
+		replacement.UserID,
+		replacement.FamilyID,
+		replacement.TokenHash,
+		replacement.ExpiresAt,
+		replacement.CreatedAt,
+	).Scan(&replacement.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the current token as consumed
+	_, err = tx.Exec(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1 WHERE id = $2",
+		replacement.ID, current.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// RevokeRefreshTokenFamily revokes the family of the refresh token with the
+// given hash, provided the token belongs to the user
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, tokenHash, userID string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE revoked_at IS NULL
+		AND family_id = (
+			SELECT family_id FROM refresh_tokens WHERE token_hash = $1 AND user_id = $2
+		)
+	`
+
+	_, err := r.db.Exec(ctx, query, tokenHash, userID)
+	return err
+}
+
+// RevokeUserRefreshTokens revokes every active refresh token of a user
+func (r *Repository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
+
+// CreatePasswordResetToken stores a new password reset token
+func (r *Repository) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+}
+
+// ResetPassword consumes an unused, unexpired reset token and sets the new
+// password hash of its user in a single transaction. Every other outstanding
+// reset token of the user is invalidated as well. It returns the user ID.
+func (r *Repository) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the token so it can only be used once
+	var userID string
+	err = tx.QueryRow(ctx, `
+		SELECT user_id
+		FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, tokenHash).Scan(&userID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrResetTokenNotFound
+		}
+		return "", err
+	}
+
+	// Update password
+	_, err = tx.Exec(ctx,
+		"UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2",
+		passwordHash, userID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// Invalidate all outstanding reset tokens of the user
+	_, err = tx.Exec(ctx,
+		"UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL",
+		userID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// CreateEmailVerificationToken stores a new email verification token
+func (r *Repository) CreateEmailVerificationToken(ctx context.Context, token *EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+}
+
+// VerifyEmail consumes an unused, unexpired verification token and marks the
+// email of its user as verified in a single transaction. It returns the user ID.
+func (r *Repository) VerifyEmail(ctx context.Context, tokenHash string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the token so it can only be used once
+	var userID string
+	err = tx.QueryRow(ctx, `
+		SELECT user_id
+		FROM email_verification_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, tokenHash).Scan(&userID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrVerificationTokenNotFound
+		}
+		return "", err
+	}
+
+	// Mark email as verified
+	_, err = tx.Exec(ctx,
+		"UPDATE users SET email_verified_at = NOW(), updated_at = NOW() WHERE id = $1 AND email_verified_at IS NULL",
+		userID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// Invalidate all outstanding verification tokens of the user
+	_, err = tx.Exec(ctx,
+		"UPDATE email_verification_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL",
+		userID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// SetPendingTOTPSecret stores a TOTP secret awaiting confirmation. It fails
+// with ErrUserNotFound if the user already has two-factor authentication enabled.
+func (r *Repository) SetPendingTOTPSecret(ctx context.Context, userID, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_last_step = 0, updated_at = NOW()
+		WHERE id = $2 AND totp_enabled_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, secret, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// EnableTOTP confirms the pending TOTP secret of a user and replaces their
+// recovery codes in a single transaction
+func (r *Repository) EnableTOTP(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Enable two-factor authentication
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET totp_enabled_at = NOW(), totp_last_step = $1, updated_at = NOW()
+		WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`, step, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	// Replace recovery codes
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(ctx,
+			"INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userID, hash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DisableTOTP removes the TOTP secret and recovery codes of a user
+func (r *Repository) DisableTOTP(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Clear TOTP secret
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = NOW()
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	// Remove recovery codes
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records the time step of an accepted TOTP code. It returns
+// ErrTOTPStepUsed if a code from the same or a later step was already accepted,
+// so each code can only be used once.
+func (r *Repository) UseTOTPStep(ctx context.Context, userID string, step int64) error {
+	query := `
+		UPDATE users
+		SET totp_last_step = $1
+		WHERE id = $2 AND totp_last_step < $1
+	`
+
+	result, err := r.db.Exec(ctx, query, step, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTOTPStepUsed
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes an unused recovery code of a user
+func (r *Repository) UseRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	query := `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// GetByIdentity retrieves the user linked to an external provider subject
+func (r *Repository) GetByIdentity(ctx context.Context, provider, subject string) (*User, error) {
+	user := &User{}
+
+	query := `
+		SELECT u.id, u.email, u.email_verified_at, u.password_hash, u.name, u.roles,
+			COALESCE(u.totp_secret, ''), u.totp_enabled_at, u.totp_last_step, u.created_at, u.updated_at
+		FROM user_identities i
+		JOIN users u ON u.id = i.user_id
+		WHERE i.provider = $1 AND i.subject = $2
+	`
+
+	err := r.db.QueryRow(ctx, query, provider, subject).Scan(
+		&user.ID,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.PasswordHash,
+		&user.Name,
+		&user.Roles,
+		&user.TOTPSecret,
+		&user.TOTPEnabledAt,
+		&user.TOTPLastStep,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateIdentity links an existing user to an external provider subject
+func (r *Repository) CreateIdentity(ctx context.Context, identity *UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	return r.db.QueryRow(ctx, query,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	).Scan(&identity.ID)
+}
+
+// CreateWithIdentity inserts a new user together with its external identity
+func (r *Repository) CreateWithIdentity(ctx context.Context, user *User, identity *UserIdentity) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Check if email already exists
+	var count int
+	err = tx.QueryRow(ctx,
+		"SELECT COUNT(*) FROM users WHERE email = $1",
+		user.Email,
+	).Scan(&count)
+
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrEmailAlreadyExists
+	}
+
+	// Insert user
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (email, email_verified_at, password_hash, name, roles, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`,
+		user.Email,
+		user.EmailVerifiedAt,
+		user.PasswordHash,
+		user.Name,
+		user.Roles,
+		user.CreatedAt,
+		user.UpdatedAt,
+	).Scan(&user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Link identity
+	identity.UserID = user.ID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	).Scan(&identity.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
\ No newline at end of file
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// newTestRepository connects to the database in TEST_DATABASE_URL and
+// migrates it, skipping the test when the variable isn't set
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	m, err := migrate.New("file://../../infrastructure/database/migrations/postgres", dsn)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrate up: %v", err)
+	}
+	m.Close()
+
+	db, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	return NewRepository(db)
+}
+
+// newTestUser stores a user with a unique email
+func newTestUser(t *testing.T, repo *Repository) *User {
+	t.Helper()
+
+	email := "refresh-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "@example.com"
+	user, err := NewUser(email, "password", "Refresh Test")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return user
+}
+
+func TestRotateRefreshTokenReuseRevokesFamily(t *testing.T) {
+	repo := newTestRepository(t)
+	user := newTestUser(t, repo)
+	ctx := context.Background()
+
+	first, _, err := NewRefreshToken(user.ID, "", time.Hour)
+	if err != nil {
+		t.Fatalf("NewRefreshToken: %v", err)
+	}
+	if err := repo.CreateRefreshToken(ctx, first); err != nil {
+		t.Fatalf("CreateRefreshToken: %v", err)
+	}
+
+	// Rotate the first token into a second one
+	second, _, _ := NewRefreshToken("", "", time.Hour)
+	if _, err := repo.RotateRefreshToken(ctx, first.TokenHash, second); err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if second.FamilyID != first.FamilyID {
+		t.Fatalf("replacement family = %s, want %s", second.FamilyID, first.FamilyID)
+	}
+
+	// Presenting the consumed first token again is reuse
+	third, _, _ := NewRefreshToken("", "", time.Hour)
+	if _, err := repo.RotateRefreshToken(ctx, first.TokenHash, third); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a consumed token = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// The second token, still unused, was revoked with its family
+	fourth, _, _ := NewRefreshToken("", "", time.Hour)
+	if _, err := repo.RotateRefreshToken(ctx, second.TokenHash, fourth); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("rotating a token of a reused family = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// Other families of the user are untouched
+	other, _, _ := NewRefreshToken(user.ID, "", time.Hour)
+	if err := repo.CreateRefreshToken(ctx, other); err != nil {
+		t.Fatalf("CreateRefreshToken: %v", err)
+	}
+	next, _, _ := NewRefreshToken("", "", time.Hour)
+	if _, err := repo.RotateRefreshToken(ctx, other.TokenHash, next); err != nil {
+		t.Fatalf("rotating a token of another family: %v", err)
+	}
+}
\ No newline at end of file
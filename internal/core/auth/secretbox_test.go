@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecretBoxRoundTrip(t *testing.T) {
+	box := newSecretBox("key")
+
+	sealed, err := box.seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+		t.Fatalf("sealed value %q", sealed)
+	}
+
+	secret, err := box.open(sealed)
+	if err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("open = %q, %v", secret, err)
+	}
+}
+
+func TestSecretBoxRejectsUnsealedValues(t *testing.T) {
+	box := newSecretBox("key")
+	sealed, _ := box.seal("JBSWY3DPEHPK3PXP")
+
+	for _, value := range []string{
+		"JBSWY3DPEHPK3PXP",     // Unprefixed plaintext
+		sealedPrefix + "!!!",   // Not base64
+		sealedPrefix + "AAAA",  // Shorter than a nonce
+		sealed[:len(sealed)-2], // Truncated ciphertext
+	} {
+		if _, err := box.open(value); err != ErrCorruptSecret {
+			t.Errorf("open(%q) = %v, want ErrCorruptSecret", value, err)
+		}
+	}
+
+	if _, err := newSecretBox("other key").open(sealed); err != ErrCorruptSecret {
+		t.Errorf("open with another key = %v, want ErrCorruptSecret", err)
+	}
+}
\ No newline at end of file
@@ -3,25 +3,73 @@ package auth
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/internal/infrastructure/mail"
+	"github.com/ivmello/go-api-template/pkg/auth"
+	"github.com/ivmello/go-api-template/pkg/totp"
+)
+
+const (
+	// emailTimeout bounds the delivery of emails sent in the background
+	emailTimeout = 30 * time.Second
+
+	// recoveryCodeCount is the number of recovery codes issued when enabling 2FA
+	recoveryCodeCount = 10
+
+	// totpSkew is the number of time steps of clock drift tolerated on TOTP codes
+	totpSkew = 1
+)
+
+// Values of config.AuthConfig.RequireVerifiedEmailFor
+const (
+	RequireVerifiedEmailForLogin    = "login"
+	RequireVerifiedEmailForMessages = "messages"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrInvalidResetToken   = errors.New("invalid or expired password reset token")
+	ErrEmailNotVerified    = errors.New("email address has not been verified")
+	ErrInvalidVerifyToken  = errors.New("invalid or expired email verification token")
+	ErrTOTPAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnrolled     = errors.New("two-factor authentication enrollment has not been started")
+	ErrTOTPNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode      = errors.New("invalid two-factor authentication code")
+	ErrInvalidMFAToken     = errors.New("invalid or expired two-factor challenge token")
 )
 
 // Service provides authentication operations
 type Service struct {
-	repo *Repository
-	jwt  config.JWTConfig
+	repo        *Repository
+	revocations *RevocationStore
+	throttle    *LoginThrottle
+	keys        *auth.KeyManager
+	secrets     *secretBox
+	mailer      mail.Sender
+	logger      *slog.Logger
+	jwt         config.JWTConfig
+	cfg         config.AuthConfig
 }
 
 // NewService creates a new authentication service
-func NewService(repo *Repository, jwtConfig config.JWTConfig) *Service {
+func NewService(repo *Repository, revocations *RevocationStore, throttle *LoginThrottle, keys *auth.KeyManager, mailer mail.Sender, logger *slog.Logger, jwtConfig config.JWTConfig, authConfig config.AuthConfig) *Service {
 	return &Service{
-		repo: repo,
-		jwt:  jwtConfig,
+		repo:        repo,
+		revocations: revocations,
+		throttle:    throttle,
+		keys:        keys,
+		secrets:     newSecretBox(authConfig.TOTPEncryptionKey),
+		mailer:      mailer,
+		logger:      logger,
+		jwt:         jwtConfig,
+		cfg:         authConfig,
 	}
 }
 
@@ -38,35 +86,545 @@ func (s *Service) Register(ctx context.Context, email, password, name string) (*
 		return nil, err
 	}
 
+	// Send verification email
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *Service) Login(ctx context.Context, email, password string) (string, error) {
+// Login authenticates a user with their password. Users with two-factor
+// authentication get a challenge to complete with VerifyMFA; everyone else
+// gets an access token and a refresh token. Repeated failures for the email
+// or client IP return a *LockedError until the backoff delay has passed.
+func (s *Service) Login(ctx context.Context, email, password, clientIP string) (*LoginResult, error) {
+	// Count the attempt, rejecting blocked ones before spending time on bcrypt
+	if err := s.throttle.Attempt(ctx, email, clientIP); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
-			return "", ErrInvalidCredentials
+			return nil, s.loginFailed(ctx, email, clientIP, ErrInvalidCredentials)
 		}
-		return "", err
+		return nil, err
 	}
 
 	// Check password
 	if !user.ComparePassword(password) {
-		return "", ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, email, clientIP, ErrInvalidCredentials)
+	}
+
+	// Check email verification
+	if s.cfg.RequireVerifiedEmailFor == RequireVerifiedEmailForLogin && !user.IsEmailVerified() {
+		if err := s.throttle.Release(ctx, email, clientIP); err != nil {
+			return nil, err
+		}
+		return nil, ErrEmailNotVerified
+	}
+
+	// Require the second factor; failures are only cleared once it passes
+	if user.HasTOTP() {
+		if err := s.throttle.Release(ctx, email, clientIP); err != nil {
+			return nil, err
+		}
+		challenge, err := s.newMFAChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge}, nil
+	}
+
+	if err := s.throttle.Reset(ctx, email, clientIP); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// VerifyMFA completes a two-factor login by exchanging a challenge token and a
+// TOTP or recovery code for an access token and a refresh token. Wrong codes
+// count as failed logins.
+func (s *Service) VerifyMFA(ctx context.Context, challengeToken, code, clientIP string) (*TokenPair, error) {
+	// Parse and validate challenge
+	claims, err := s.keys.ValidateToken(challengeToken)
+	if err != nil || claims.Purpose != auth.PurposeMFAChallenge {
+		return nil, ErrInvalidMFAToken
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID, claims.UserID, claims.IssuedAt.Time)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidMFAToken
 	}
 
-	// Generate JWT token
-	token, err := user.GenerateToken(s.jwt.ExpirationHours)
+	// Load the user
+	user, err := s.repo.GetByID(ctx, claims.UserID)
 	if err != nil {
-		return "", err
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if !user.HasTOTP() {
+		return nil, ErrInvalidMFAToken
+	}
+
+	// Check second factor
+	if err := s.throttle.Attempt(ctx, user.Email, clientIP); err != nil {
+		return nil, err
+	}
+	if err := s.checkMFACode(ctx, user, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			return nil, s.loginFailed(ctx, user.Email, clientIP, err)
+		}
+		return nil, err
+	}
+	if err := s.throttle.Reset(ctx, user.Email, clientIP); err != nil {
+		return nil, err
+	}
+
+	// Challenges are single-use
+	if err := s.revocations.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
 	}
 
-	return token, nil
+	return s.startSession(ctx, user)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is rotated; presenting it again revokes every token issued from the same login.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	// Prepare the replacement token
+	replacement, plaintext, err := NewRefreshToken("", "", s.jwt.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rotate the presented token
+	current, err := s.repo.RotateRefreshToken(ctx, auth.HashToken(refreshToken), replacement)
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) || errors.Is(err, ErrRefreshTokenExpired) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// Load the token owner
+	user, err := s.repo.GetByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return s.newTokenPair(user, plaintext)
+}
+
+// ValidateAccessToken validates an access token and checks it against the
+// revocation denylist
+func (s *Service) ValidateAccessToken(ctx context.Context, token string) (*auth.Claims, error) {
+	// Parse and validate token
+	claims, err := s.keys.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject tokens issued for other purposes, such as MFA challenges
+	if claims.Purpose != "" {
+		return nil, auth.ErrInvalidToken
+	}
+
+	// Check revocation
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID, claims.UserID, issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// Logout revokes the access token described by the claims and, when given,
+// the refresh token family it was issued with
+func (s *Service) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	// Revoke access token
+	expiresAt := time.Now().Add(s.jwt.AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.revocations.RevokeToken(ctx, claims.ID, expiresAt); err != nil {
+		return err
+	}
+
+	// Revoke refresh token family
+	if refreshToken != "" {
+		return s.repo.RevokeRefreshTokenFamily(ctx, auth.HashToken(refreshToken), claims.UserID)
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every access and refresh token issued to the user
+func (s *Service) LogoutAll(ctx context.Context, userID string) error {
+	// Revoke refresh tokens first so no new access tokens can be minted
+	if err := s.repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.revocations.RevokeAllForUser(ctx, userID, s.jwt.AccessTokenTTL)
+}
+
+// VerifyEmail marks the email of the token's user as verified
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	_, err := s.repo.VerifyEmail(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrVerificationTokenNotFound) {
+			return ErrInvalidVerifyToken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link to an unverified
+// account. Unknown and already verified emails are silently ignored.
+func (s *Service) ResendVerificationEmail(ctx context.Context, email string) error {
+	// Get user by email
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// EnrollTOTP starts two-factor enrollment by generating a new secret. It
+// stays inactive until confirmed with a code from the authenticator app.
+func (s *Service) EnrollTOTP(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	// Get user
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasTOTP() {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	// Store pending secret
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.secrets.seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetPendingTOTPSecret(ctx, user.ID, sealed); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.cfg.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves their
+// authenticator app produces valid codes. It returns the recovery codes,
+// which are shown only once.
+func (s *Service) ConfirmTOTP(ctx context.Context, userID, code string) ([]string, error) {
+	// Get user
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasTOTP() {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	// Check code against the pending secret
+	secret, err := s.secrets.open(user.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	// Enable with fresh recovery codes
+	codes, hashes, err := NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTOTP(ctx, user.ID, step, hashes); err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrTOTPNotEnrolled
+		}
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor authentication after checking the user's
+// password and a current TOTP or recovery code
+func (s *Service) DisableTOTP(ctx context.Context, userID, password, code string) error {
+	// Get user
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasTOTP() {
+		return ErrTOTPNotEnabled
+	}
+
+	// Check both factors
+	if !user.ComparePassword(password) {
+		return ErrInvalidCredentials
+	}
+	if err := s.checkMFACode(ctx, user, code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTOTP(ctx, user.ID)
+}
+
+// RequestPasswordReset emails a single-use reset link to the user. Unknown
+// emails are silently ignored so callers cannot probe for accounts.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	// Get user by email
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Store hashed reset token
+	resetToken, plaintext, err := NewPasswordResetToken(user.ID, s.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreatePasswordResetToken(ctx, resetToken); err != nil {
+		return err
+	}
+
+	// Send email in the background so response times do not reveal whether the account exists
+	s.sendEmail(passwordResetEmail(user, s.cfg.PasswordResetURL, plaintext, s.cfg.PasswordResetTTL))
+
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and revokes every
+// existing session of the user
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// Hash new password
+	passwordHash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Consume token and update password
+	userID, err := s.repo.ResetPassword(ctx, auth.HashToken(token), passwordHash)
+	if err != nil {
+		if errors.Is(err, ErrResetTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// Revoke existing sessions
+	return s.LogoutAll(ctx, userID)
+}
+
+// SetUserRoles replaces the roles of a user. The change applies to access
+// tokens issued from then on, including those minted by a refresh.
+func (s *Service) SetUserRoles(ctx context.Context, userID string, roles []string) (*User, error) {
+	// Validate roles
+	if len(roles) == 0 {
+		return nil, ErrInvalidRole
+	}
+	for _, role := range roles {
+		if !auth.IsValidRole(role) {
+			return nil, ErrInvalidRole
+		}
+	}
+
+	// Update roles
+	return s.repo.UpdateRoles(ctx, userID, roles)
+}
+
+// JWKS returns the public keys that verify issued tokens
+func (s *Service) JWKS() auth.JWKSet {
+	return s.keys.JWKS()
 }
 
 // GetUserByID gets a user by ID
 func (s *Service) GetUserByID(ctx context.Context, id string) (*User, error) {
 	return s.repo.GetByID(ctx, id)
+}
+
+// loginFailed records a failed login attempt, logs lockouts and returns cause
+func (s *Service) loginFailed(ctx context.Context, email, clientIP string, cause error) error {
+	failures, err := s.throttle.RecordFailure(ctx, email, clientIP)
+	if err != nil {
+		return err
+	}
+
+	if failures.Email >= int64(s.cfg.LoginMaxFailures) {
+		s.logger.Warn("Account locked after repeated failed logins",
+			"email", email,
+			"client_ip", clientIP,
+			"failures", failures.Email,
+			"lockout", s.cfg.LoginLockoutDuration,
+		)
+	}
+	if failures.IP >= int64(s.cfg.LoginMaxFailuresPerIP) {
+		s.logger.Warn("Client IP locked after repeated failed logins",
+			"client_ip", clientIP,
+			"failures", failures.IP,
+			"lockout", s.cfg.LoginLockoutDuration,
+		)
+	}
+
+	return cause
+}
+
+// startSession starts a new refresh token family and returns its first token pair
+func (s *Service) startSession(ctx context.Context, user *User) (*TokenPair, error) {
+	refreshToken, plaintext, err := NewRefreshToken(user.ID, "", s.jwt.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return s.newTokenPair(user, plaintext)
+}
+
+// newMFAChallenge issues a short-lived token proving the password step of a login
+func (s *Service) newMFAChallenge(user *User) (*MFAChallenge, error) {
+	token, err := s.keys.GenerateToken(auth.Claims{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Purpose: auth.PurposeMFAChallenge,
+	}, s.cfg.MFAChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAChallenge{
+		Token:     token,
+		ExpiresAt: time.Now().Add(s.cfg.MFAChallengeTTL),
+	}, nil
+}
+
+// checkMFACode accepts a TOTP code or an unused recovery code. Each code is
+// accepted only once.
+func (s *Service) checkMFACode(ctx context.Context, user *User, code string) error {
+	// TOTP codes are all digits; anything else is treated as a recovery code
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		secret, err := s.secrets.open(user.TOTPSecret)
+		if err != nil {
+			return err
+		}
+		step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		if err := s.repo.UseTOTPStep(ctx, user.ID, step); err != nil {
+			if errors.Is(err, ErrTOTPStepUsed) {
+				return ErrInvalidMFACode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := s.repo.UseRecoveryCode(ctx, user.ID, HashRecoveryCode(code)); err != nil {
+		if errors.Is(err, ErrRecoveryCodeNotFound) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+
+	s.logger.Info("Recovery code used", "user_id", user.ID)
+	return nil
+}
+
+// sendVerificationEmail stores a new verification token and emails its link
+func (s *Service) sendVerificationEmail(ctx context.Context, user *User) error {
+	// Store hashed verification token
+	verificationToken, plaintext, err := NewEmailVerificationToken(user.ID, s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateEmailVerificationToken(ctx, verificationToken); err != nil {
+		return err
+	}
+
+	s.sendEmail(verificationEmail(user, s.cfg.EmailVerificationURL, plaintext, s.cfg.EmailVerificationTTL))
+	return nil
+}
+
+// sendEmail delivers an email in the background, logging delivery failures
+func (s *Service) sendEmail(msg mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), emailTimeout)
+		defer cancel()
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.logger.Error("Failed to send email", "subject", msg.Subject, "error", err)
+		}
+	}()
+}
+
+// newTokenPair issues a short-lived access token for the user and pairs it
+// with an already stored refresh token
+func (s *Service) newTokenPair(user *User, refreshToken string) (*TokenPair, error) {
+	expiresAt := time.Now().Add(s.jwt.AccessTokenTTL)
+
+	accessToken, err := user.GenerateToken(s.keys, s.jwt.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
 }
\ No newline at end of file
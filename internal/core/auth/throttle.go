@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKeyPrefix = "auth:login:failures:"
+	loginBlockedKeyPrefix  = "auth:login:blocked:"
+
+	// attemptHold bounds how long an attempt at the maximum failures holds
+	// its subject when it never completes
+	attemptHold = 10 * time.Second
+)
+
+// LockedError is returned while an email or client IP is blocked after
+// repeated failed logins
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns the wait in whole seconds, rounded up, as used by
+// the Retry-After header
+func (e *LockedError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// LoginFailures holds the consecutive failure counts after a failed login
+type LoginFailures struct {
+	Email int64
+	IP    int64
+}
+
+// LoginThrottle counts failed logins per email and per client IP in Redis.
+// Each failure blocks further attempts for an exponentially growing delay,
+// and reaching the maximum number of failures locks the subject out.
+type LoginThrottle struct {
+	client *redis.Client
+	cfg    config.AuthConfig
+}
+
+// NewLoginThrottle creates a new login throttle
+func NewLoginThrottle(client *redis.Client, cfg config.AuthConfig) *LoginThrottle {
+	return &LoginThrottle{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// attemptScript checks and counts a login attempt in one step, so parallel
+// attempts can't all pass the check before any failure is recorded. KEYS
+// holds the failures and blocked keys of each subject in turn, and ARGV the
+// maximum failures of each subject followed by the failure window and the
+// attempt hold in milliseconds. An attempt reaching the maximum failures of
+// a subject holds it until the attempt completes, so attempts past the
+// maximum are made one at a time. It returns 0 with the milliseconds until
+// an attempt is allowed, or 1 when the attempt was counted.
+var attemptScript = redis.NewScript(`
+local subjects = #KEYS / 2
+local window = tonumber(ARGV[subjects + 1])
+local hold = tonumber(ARGV[subjects + 2])
+
+local retry = 0
+for i = 1, subjects do
+	local ttl = redis.call("PTTL", KEYS[2 * i])
+	if ttl > retry then
+		retry = ttl
+	end
+end
+if retry > 0 then
+	return {0, retry}
+end
+
+for i = 1, subjects do
+	local count = redis.call("INCR", KEYS[2 * i - 1])
+	redis.call("PEXPIRE", KEYS[2 * i - 1], window)
+	if count >= tonumber(ARGV[i]) then
+		redis.call("SET", KEYS[2 * i], "attempt", "PX", hold)
+	end
+end
+return {1, 0}
+`)
+
+// releaseScript takes back an attempt that didn't fail. KEYS holds the
+// failures and blocked keys of each subject in turn; blocks are only lifted
+// when they are the hold of the attempt.
+var releaseScript = redis.NewScript(`
+for i = 1, #KEYS, 2 do
+	if tonumber(redis.call("GET", KEYS[i]) or "0") > 0 then
+		redis.call("DECR", KEYS[i])
+	end
+	if redis.call("GET", KEYS[i + 1]) == "attempt" then
+		redis.call("DEL", KEYS[i + 1])
+	end
+end
+return 0
+`)
+
+// Attempt counts a login attempt against the email and the client IP, or
+// returns a *LockedError without counting it if either is blocked. Attempts
+// are counted as failures up front; call Release or Reset when the attempt
+// turns out not to be a failure.
+func (t *LoginThrottle) Attempt(ctx context.Context, email, clientIP string) error {
+	subjects := t.subjects(email, clientIP)
+
+	keys := make([]string, 0, 2*len(subjects))
+	args := make([]interface{}, 0, len(subjects)+2)
+	for _, subject := range subjects {
+		keys = append(keys, loginFailuresKeyPrefix+subject.key, loginBlockedKeyPrefix+subject.key)
+		args = append(args, subject.maxFailures)
+	}
+	args = append(args, t.cfg.LoginFailureWindow.Milliseconds(), attemptHold.Milliseconds())
+
+	values, err := attemptScript.Run(ctx, t.client, keys, args...).Int64Slice()
+	if err != nil {
+		return err
+	}
+	if values[0] == 0 {
+		return &LockedError{RetryAfter: time.Duration(values[1]) * time.Millisecond}
+	}
+	return nil
+}
+
+// Release takes back an attempt that didn't fail, such as a correct password
+// awaiting its second factor
+func (t *LoginThrottle) Release(ctx context.Context, email, clientIP string) error {
+	var keys []string
+	for _, subject := range t.subjects(email, clientIP) {
+		keys = append(keys, loginFailuresKeyPrefix+subject.key, loginBlockedKeyPrefix+subject.key)
+	}
+	return releaseScript.Run(ctx, t.client, keys).Err()
+}
+
+// RecordFailure blocks the email and client IP of a failed attempt for their
+// backoff delay, and returns their failure counts including the attempt
+func (t *LoginThrottle) RecordFailure(ctx context.Context, email, clientIP string) (LoginFailures, error) {
+	subjects := t.subjects(email, clientIP)
+
+	// Read failure counters, which Attempt already incremented
+	pipe := t.client.Pipeline()
+	counts := make([]*redis.StringCmd, len(subjects))
+	for i, subject := range subjects {
+		counts[i] = pipe.Get(ctx, loginFailuresKeyPrefix+subject.key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return LoginFailures{}, err
+	}
+
+	// Block each subject for its backoff delay
+	var failures LoginFailures
+	pipe = t.client.Pipeline()
+	for i, subject := range subjects {
+		count, err := counts[i].Int64()
+		if err != nil || count < 1 {
+			count = 1 // Expired between the attempt and its failure
+		}
+		pipe.Set(ctx, loginBlockedKeyPrefix+subject.key, 1, t.delay(count, subject.maxFailures))
+
+		if subject.isIP {
+			failures.IP = count
+		} else {
+			failures.Email = count
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return LoginFailures{}, err
+	}
+
+	return failures, nil
+}
+
+// Reset clears the failures of an email after a successful login and takes
+// back the attempt counted for the client IP. Earlier client IP failures are
+// kept so one valid account cannot unlock an attacking IP.
+func (t *LoginThrottle) Reset(ctx context.Context, email, clientIP string) error {
+	if clientIP != "" {
+		key := ipSubject(clientIP)
+		if err := releaseScript.Run(ctx, t.client, []string{loginFailuresKeyPrefix + key, loginBlockedKeyPrefix + key}).Err(); err != nil {
+			return err
+		}
+	}
+
+	key := emailSubject(email)
+	return t.client.Del(ctx, loginFailuresKeyPrefix+key, loginBlockedKeyPrefix+key).Err()
+}
+
+// delay returns how long a subject is blocked after its nth consecutive failure
+func (t *LoginThrottle) delay(failures int64, maxFailures int) time.Duration {
+	if failures >= int64(maxFailures) {
+		return t.cfg.LoginLockoutDuration
+	}
+
+	// Double the delay on each failure, up to the maximum backoff
+	delay := t.cfg.LoginBackoffBase << (failures - 1)
+	if delay <= 0 || delay > t.cfg.LoginBackoffMax {
+		return t.cfg.LoginBackoffMax
+	}
+	return delay
+}
+
+// throttleSubject is an email or client IP that failures are counted for
+type throttleSubject struct {
+	key         string
+	maxFailures int
+	isIP        bool
+}
+
+// subjects returns the subjects of a login attempt. The client IP is skipped
+// when unknown.
+func (t *LoginThrottle) subjects(email, clientIP string) []throttleSubject {
+	subjects := []throttleSubject{
+		{key: emailSubject(email), maxFailures: t.cfg.LoginMaxFailures},
+	}
+	if clientIP != "" {
+		subjects = append(subjects, throttleSubject{
+			key:         ipSubject(clientIP),
+			maxFailures: t.cfg.LoginMaxFailuresPerIP,
+			isIP:        true,
+		})
+	}
+	return subjects
+}
+
+// ipSubject returns the throttle key of a client IP
+func ipSubject(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+// emailSubject returns the throttle key of an email
+func emailSubject(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
\ No newline at end of file
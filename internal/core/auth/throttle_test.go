@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestThrottle(t *testing.T, maxFailures int) *LoginThrottle {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewLoginThrottle(client, config.AuthConfig{
+		LoginMaxFailures:      maxFailures,
+		LoginMaxFailuresPerIP: 100,
+		LoginBackoffBase:      time.Second,
+		LoginBackoffMax:       time.Minute,
+		LoginLockoutDuration:  15 * time.Minute,
+		LoginFailureWindow:    time.Hour,
+	})
+}
+
+func TestLoginThrottleParallelAttempts(t *testing.T) {
+	throttle := newTestThrottle(t, 3)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := throttle.Attempt(ctx, "alice@example.com", "203.0.113.7")
+			var locked *LockedError
+			if err != nil && !errors.As(err, &locked) {
+				t.Errorf("Attempt: %v", err)
+				return
+			}
+			if err == nil {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 3 {
+		t.Fatalf("%d parallel attempts were allowed, want 3", allowed)
+	}
+}
+
+func TestLoginThrottleFailureBlocks(t *testing.T) {
+	throttle := newTestThrottle(t, 3)
+	ctx := context.Background()
+
+	if err := throttle.Attempt(ctx, "alice@example.com", "203.0.113.7"); err != nil {
+		t.Fatalf("Attempt: %v", err)
+	}
+	failures, err := throttle.RecordFailure(ctx, "alice@example.com", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+	if failures.Email != 1 || failures.IP != 1 {
+		t.Fatalf("failures = %+v, want 1 each", failures)
+	}
+
+	var locked *LockedError
+	err = throttle.Attempt(ctx, "ALICE@example.com", "198.51.100.1")
+	if !errors.As(err, &locked) {
+		t.Fatalf("Attempt after a failure = %v, want *LockedError", err)
+	}
+	if locked.RetryAfter <= 0 || locked.RetryAfter > time.Second {
+		t.Fatalf("RetryAfter = %s, want the base backoff", locked.RetryAfter)
+	}
+}
+
+func TestLoginThrottleSuccessLiftsHold(t *testing.T) {
+	throttle := newTestThrottle(t, 1)
+	ctx := context.Background()
+
+	// The attempt reaching the maximum holds the email while in flight
+	if err := throttle.Attempt(ctx, "alice@example.com", "203.0.113.7"); err != nil {
+		t.Fatalf("Attempt: %v", err)
+	}
+	var locked *LockedError
+	if err := throttle.Attempt(ctx, "alice@example.com", "203.0.113.7"); !errors.As(err, &locked) {
+		t.Fatalf("parallel Attempt = %v, want *LockedError", err)
+	}
+
+	if err := throttle.Reset(ctx, "alice@example.com", "203.0.113.7"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if err := throttle.Attempt(ctx, "alice@example.com", "203.0.113.7"); err != nil {
+		t.Fatalf("Attempt after a successful login: %v", err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,161 @@
+package channel
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/ivmello/go-api-template/pkg/validator"
+)
+
+// Channel visibilities. Anyone can join a public channel; private channels
+// are only visible to their members and are joined by invitation.
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+// Member roles
+const (
+	RoleOwner  = "owner"
+	RoleMember = "member"
+)
+
+// DefaultChannelID is the public channel that messages written before
+// channels existed were moved to
+const DefaultChannelID = "00000000-0000-0000-0000-000000000001"
+
+// MaxNameLength bounds the length of a channel name
+const MaxNameLength = 80
+
+// Channel represents a room that messages are posted to
+type Channel struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewChannel creates a new channel created by the given user
+func NewChannel(name, description, visibility, createdBy string) *Channel {
+	now := time.Now()
+	return &Channel{
+		Name:        name,
+		Description: description,
+		Visibility:  visibility,
+		CreatedBy:   &createdBy,
+		MemberCount: 1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// IsPrivate reports whether the channel is only visible to its members
+func (c *Channel) IsPrivate() bool {
+	return c.Visibility == VisibilityPrivate
+}
+
+// IsValidVisibility reports whether the visibility is known
+func IsValidVisibility(visibility string) bool {
+	return visibility == VisibilityPublic || visibility == VisibilityPrivate
+}
+
+// Member is a user's membership of a channel
+type Member struct {
+	ChannelID string    `json:"channel_id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// NewMember creates a new membership with the given role
+func NewMember(channelID, userID, role string) *Member {
+	return &Member{
+		ChannelID: channelID,
+		UserID:    userID,
+		Role:      role,
+		JoinedAt:  time.Now(),
+	}
+}
+
+// IsOwner reports whether the member owns the channel
+func (m *Member) IsOwner() bool {
+	return m.Role == RoleOwner
+}
+
+// Page size limits for listing channels and members
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListOptions filters and paginates a channel listing
+type ListOptions struct {
+	UserID string // The user listing channels, who sees their private ones
+	Joined bool   // Only channels the user is a member of
+	Limit  int
+	Cursor string
+}
+
+// Page is one page of a channel listing, ordered by name. NextCursor is empty
+// on the last page.
+type Page struct {
+	Channels   []*Channel
+	NextCursor string
+}
+
+// MemberPage is one page of a channel's members, in the order they joined.
+// NextCursor is empty on the last page.
+type MemberPage struct {
+	Members    []*Member
+	NextCursor string
+}
+
+// nameCursor is the keyset position after the last channel of a page
+type nameCursor struct {
+	Name string `json:"n"`
+	ID   string `json:"id"`
+}
+
+// memberCursor is the keyset position after the last member of a page
+type memberCursor struct {
+	Time   time.Time `json:"t"`
+	UserID string    `json:"id"`
+}
+
+// encodeCursor returns the opaque cursor for a keyset position
+func encodeCursor(position interface{}) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeNameCursor parses an opaque channel listing cursor
+func decodeNameCursor(s string) (*nameCursor, error) {
+	c := &nameCursor{}
+	if err := decodeCursor(s, c); err != nil || validator.ValidateUUID(c.ID) != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// decodeMemberCursor parses an opaque member listing cursor
+func decodeMemberCursor(s string) (*memberCursor, error) {
+	c := &memberCursor{}
+	if err := decodeCursor(s, c); err != nil || validator.ValidateUUID(c.UserID) != nil || c.Time.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// decodeCursor decodes an opaque cursor into its keyset position
+func decodeCursor(s string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	return json.Unmarshal(data, position)
+}
\ No newline at end of file
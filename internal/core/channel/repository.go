@@ -0,0 +1,353 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/core/unread"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrChannelNotFound = errors.New("channel not found")
+	ErrNameTaken       = errors.New("channel name is already taken")
+	ErrNotMember       = errors.New("not a member of the channel")
+	ErrLastOwner       = errors.New("the last owner can't leave a channel that still has members")
+	ErrMessageNotFound = errors.New("message not found")
+	ErrInvalidCursor   = errors.New("invalid pagination cursor")
+)
+
+// channelColumns lists the columns read by scanChannel, in order, for a query
+// on channels aliased as c
+const channelColumns = `c.id, c.name, c.description, c.visibility, c.created_by,
+	(SELECT COUNT(*) FROM channel_members cm WHERE cm.channel_id = c.id), c.created_at, c.updated_at`
+
+// Repository provides access to channel and membership storage
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new channel repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Create inserts a new channel into the database together with its owner's
+// membership
+func (r *Repository) Create(ctx context.Context, channel *Channel, owner string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Check if name already exists
+	var count int
+	err = tx.QueryRow(ctx,
+		"SELECT COUNT(*) FROM channels WHERE LOWER(name) = LOWER($1)",
+		channel.Name,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrNameTaken
+	}
+
+	// Insert channel
+	err = tx.QueryRow(ctx, `
+		INSERT INTO channels (name, description, visibility, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`,
+		channel.Name,
+		channel.Description,
+		channel.Visibility,
+		channel.CreatedBy,
+		channel.CreatedAt,
+		channel.UpdatedAt,
+	).Scan(&channel.ID)
+	if err != nil {
+		return err
+	}
+
+	// Add owner
+	_, err = tx.Exec(ctx, `
+		INSERT INTO channel_members (channel_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+	`, channel.ID, owner, RoleOwner, channel.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves a channel by ID
+func (r *Repository) GetByID(ctx context.Context, id string) (*Channel, error) {
+	query := "SELECT " + channelColumns + " FROM channels c WHERE c.id = $1"
+
+	channel, err := scanChannel(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChannelNotFound
+		}
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// List retrieves up to limit channels visible to the user, ordered by name
+// and starting after the cursor position: public channels and the private
+// ones the user is a member of, or only joined channels when requested
+func (r *Repository) List(ctx context.Context, opts ListOptions, after *nameCursor, limit int) ([]*Channel, error) {
+	var args []interface{}
+
+	// addArg appends a query argument and returns its placeholder
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Filters
+	member := "EXISTS (SELECT 1 FROM channel_members cm WHERE cm.channel_id = c.id AND cm.user_id = " + addArg(opts.UserID) + ")"
+	conditions := []string{fmt.Sprintf("(c.visibility = %s OR %s)", addArg(VisibilityPublic), member)}
+	if opts.Joined {
+		conditions = []string{member}
+	}
+
+	// Keyset pagination
+	if after != nil {
+		conditions = append(conditions, fmt.Sprintf("(LOWER(c.name), c.id) > (LOWER(%s), %s)", addArg(after.Name), addArg(after.ID)))
+	}
+
+	query := "SELECT " + channelColumns + " FROM channels c WHERE " + strings.Join(conditions, " AND ")
+	query += " ORDER BY LOWER(c.name), c.id LIMIT " + addArg(limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []*Channel
+	for rows.Next() {
+		channel, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// GetMember retrieves a user's membership of a channel
+func (r *Repository) GetMember(ctx context.Context, channelID, userID string) (*Member, error) {
+	member := &Member{}
+
+	query := `
+		SELECT channel_id, user_id, role, joined_at
+		FROM channel_members
+		WHERE channel_id = $1 AND user_id = $2
+	`
+
+	err := r.db.QueryRow(ctx, query, channelID, userID).Scan(
+		&member.ChannelID,
+		&member.UserID,
+		&member.Role,
+		&member.JoinedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// AddMember inserts a membership. Adding an existing member keeps their
+// current role.
+func (r *Repository) AddMember(ctx context.Context, member *Member) error {
+	query := `
+		INSERT INTO channel_members (channel_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (channel_id, user_id) DO NOTHING
+	`
+
+	_, err := r.db.Exec(ctx, query, member.ChannelID, member.UserID, member.Role, member.JoinedAt)
+	return err
+}
+
+// RemoveMember deletes a membership. The last owner can only leave once
+// nobody else is left in the channel.
+func (r *Repository) RemoveMember(ctx context.Context, channelID, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the channel so owners leaving at the same time are counted in turn
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM channels WHERE id = $1 FOR UPDATE", channelID); err != nil {
+		return err
+	}
+
+	var role string
+	err = tx.QueryRow(ctx,
+		"SELECT role FROM channel_members WHERE channel_id = $1 AND user_id = $2",
+		channelID, userID,
+	).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotMember
+		}
+		return err
+	}
+
+	if role == RoleOwner {
+		var owners, members int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FILTER (WHERE role = $2), COUNT(*)
+			FROM channel_members
+			WHERE channel_id = $1
+		`, channelID, RoleOwner).Scan(&owners, &members)
+		if err != nil {
+			return err
+		}
+		if owners == 1 && members > 1 {
+			return ErrLastOwner
+		}
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM channel_members WHERE channel_id = $1 AND user_id = $2", channelID, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MarkRead moves the user's last-read pointer forward to a message of the
+// channel: the given message, or else the newest message sent at or before
+// readAt. The pointer never moves back to an older message.
+func (r *Repository) MarkRead(ctx context.Context, channelID, userID, messageID string, readAt *time.Time) error {
+	err := unread.MarkRead(ctx, r.db, unread.ChannelTarget(channelID), userID, messageID, readAt)
+	if errors.Is(err, unread.ErrMessageNotFound) {
+		return ErrMessageNotFound
+	}
+	return err
+}
+
+// ListMembers retrieves up to limit members of a channel in the order they
+// joined, starting after the cursor position
+func (r *Repository) ListMembers(ctx context.Context, channelID string, after *memberCursor, limit int) ([]*Member, error) {
+	query := `
+		SELECT channel_id, user_id, role, joined_at
+		FROM channel_members
+		WHERE channel_id = $1
+			AND ($2::timestamptz IS NULL OR (joined_at, user_id) > ($2::timestamptz, $3::uuid))
+		ORDER BY joined_at, user_id
+		LIMIT $4
+	`
+
+	var afterTime *time.Time
+	var afterID *string
+	if after != nil {
+		afterTime, afterID = &after.Time, &after.UserID
+	}
+
+	rows, err := r.db.Query(ctx, query, channelID, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*Member
+	for rows.Next() {
+		member := &Member{}
+		err := rows.Scan(
+			&member.ChannelID,
+			&member.UserID,
+			&member.Role,
+			&member.JoinedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// IsMember reports whether the user is a member of the channel
+func (r *Repository) IsMember(ctx context.Context, channelID, userID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM channel_members WHERE channel_id = $1 AND user_id = $2)",
+		channelID, userID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// ListChannelIDs retrieves the IDs of every channel the user is a member of
+func (r *Repository) ListChannelIDs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.db.Query(ctx, "SELECT channel_id FROM channel_members WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// scanChannel scans a row selected with channelColumns
+func scanChannel(row pgx.Row) (*Channel, error) {
+	channel := &Channel{}
+	err := row.Scan(
+		&channel.ID,
+		&channel.Name,
+		&channel.Description,
+		&channel.Visibility,
+		&channel.CreatedBy,
+		&channel.MemberCount,
+		&channel.CreatedAt,
+		&channel.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
\ No newline at end of file
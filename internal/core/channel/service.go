@@ -0,0 +1,264 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/unread"
+)
+
+var (
+	ErrInvalidName       = errors.New("channel name must be between 1 and 80 characters")
+	ErrInvalidVisibility = errors.New("visibility must be public or private")
+	ErrInvalidPageSize   = errors.New("limit must not be negative")
+	ErrNotOwner          = errors.New("only channel owners can invite to a private channel")
+)
+
+// UserGetter loads the users invited to a channel
+type UserGetter interface {
+	GetUserByID(ctx context.Context, id string) (*auth.User, error)
+}
+
+// UnreadCounters keeps members' unread counters in step with their read
+// pointers and memberships
+type UnreadCounters interface {
+	Refresh(ctx context.Context, userID string, target unread.Target)
+	Forget(ctx context.Context, userID string, target unread.Target)
+}
+
+// Service provides channel operations
+type Service struct {
+	repo     *Repository
+	users    UserGetter
+	counters UnreadCounters
+}
+
+// NewService creates a new channel service
+func NewService(repo *Repository, users UserGetter, counters UnreadCounters) *Service {
+	return &Service{
+		repo:     repo,
+		users:    users,
+		counters: counters,
+	}
+}
+
+// Create creates a new channel owned by the user
+func (s *Service) Create(ctx context.Context, userID, name, description, visibility string) (*Channel, error) {
+	// Validate name and visibility
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return nil, ErrInvalidName
+	}
+	if visibility == "" {
+		visibility = VisibilityPublic
+	}
+	if !IsValidVisibility(visibility) {
+		return nil, ErrInvalidVisibility
+	}
+
+	// Create channel
+	channel := NewChannel(name, description, visibility, userID)
+
+	// Save channel to database
+	if err := s.repo.Create(ctx, channel, userID); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// Get retrieves a channel visible to the user. Private channels are not
+// found by users outside them.
+func (s *Service) Get(ctx context.Context, id, userID string) (*Channel, error) {
+	channel, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.IsPrivate() {
+		if _, err := s.member(ctx, id, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return channel, nil
+}
+
+// List retrieves a page of the channels visible to the user. The limit
+// defaults to DefaultPageSize and is capped at MaxPageSize.
+func (s *Service) List(ctx context.Context, opts ListOptions) (*Page, error) {
+	// Validate options
+	limit, err := pageSize(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *nameCursor
+	if opts.Cursor != "" {
+		c, err := decodeNameCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Fetch one extra channel to know whether another page follows
+	channels, err := s.repo.List(ctx, opts, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Channels: channels}
+	if len(channels) > limit {
+		page.Channels = channels[:limit]
+		last := page.Channels[limit-1]
+		page.NextCursor = encodeCursor(nameCursor{Name: last.Name, ID: last.ID})
+	}
+
+	return page, nil
+}
+
+// Join makes the user a member of a public channel. Joining a channel twice
+// has no further effect.
+func (s *Service) Join(ctx context.Context, id, userID string) error {
+	// Private channels are joined by invitation only, so they are not found
+	// by anyone but their members, who have nothing left to join
+	channel, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if channel.IsPrivate() {
+		return nil
+	}
+
+	return s.repo.AddMember(ctx, NewMember(id, userID, RoleMember))
+}
+
+// Leave ends the user's membership of a channel
+func (s *Service) Leave(ctx context.Context, id, userID string) error {
+	if err := s.repo.RemoveMember(ctx, id, userID); err != nil {
+		return err
+	}
+
+	s.counters.Forget(ctx, userID, unread.ChannelTarget(id))
+
+	return nil
+}
+
+// MarkRead marks the messages of a channel the user is a member of as read,
+// up to and including the given message, or else up to readAt. Marking
+// older messages than already read changes nothing.
+func (s *Service) MarkRead(ctx context.Context, id, userID, messageID string, readAt *time.Time) error {
+	// Check access
+	if _, err := s.repo.GetMember(ctx, id, userID); err != nil {
+		return err
+	}
+
+	if err := s.repo.MarkRead(ctx, id, userID, messageID, readAt); err != nil {
+		return err
+	}
+
+	s.counters.Refresh(ctx, userID, unread.ChannelTarget(id))
+
+	return nil
+}
+
+// Invite adds another user to a channel on behalf of a member. Any member
+// can invite to a public channel; only owners can invite to a private one.
+func (s *Service) Invite(ctx context.Context, id, userID, inviteeID string) error {
+	// Check access
+	channel, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	inviter, err := s.repo.GetMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if channel.IsPrivate() && !inviter.IsOwner() {
+		return ErrNotOwner
+	}
+
+	// The invitee must exist
+	if _, err := s.users.GetUserByID(ctx, inviteeID); err != nil {
+		return err
+	}
+
+	return s.repo.AddMember(ctx, NewMember(id, inviteeID, RoleMember))
+}
+
+// ListMembers retrieves a page of a channel's members, in the order they
+// joined. The limit defaults to DefaultPageSize and is capped at MaxPageSize.
+func (s *Service) ListMembers(ctx context.Context, id, userID string, limit int, cursor string) (*MemberPage, error) {
+	// Validate options
+	limit, err := pageSize(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *memberCursor
+	if cursor != "" {
+		c, err := decodeMemberCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Check access
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra member to know whether another page follows
+	members, err := s.repo.ListMembers(ctx, id, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MemberPage{Members: members}
+	if len(members) > limit {
+		page.Members = members[:limit]
+		last := page.Members[limit-1]
+		page.NextCursor = encodeCursor(memberCursor{Time: last.JoinedAt, UserID: last.UserID})
+	}
+
+	return page, nil
+}
+
+// IsMember reports whether the user is a member of the channel
+func (s *Service) IsMember(ctx context.Context, channelID, userID string) (bool, error) {
+	return s.repo.IsMember(ctx, channelID, userID)
+}
+
+// ChannelIDs retrieves the IDs of every channel the user is a member of
+func (s *Service) ChannelIDs(ctx context.Context, userID string) ([]string, error) {
+	return s.repo.ListChannelIDs(ctx, userID)
+}
+
+// member retrieves a membership, hiding the channel from non-members
+func (s *Service) member(ctx context.Context, channelID, userID string) (*Member, error) {
+	member, err := s.repo.GetMember(ctx, channelID, userID)
+	if errors.Is(err, ErrNotMember) {
+		return nil, ErrChannelNotFound
+	}
+	return member, err
+}
+
+// pageSize applies the default and maximum page size to a requested limit
+func pageSize(limit int) (int, error) {
+	if limit < 0 {
+		return 0, ErrInvalidPageSize
+	}
+	if limit == 0 {
+		return DefaultPageSize, nil
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize, nil
+	}
+	return limit, nil
+}
\ No newline at end of file
@@ -0,0 +1,159 @@
+package conversation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/ivmello/go-api-template/pkg/validator"
+)
+
+// Conversation kinds. There is at most one direct conversation between any
+// two users and its participants never change; group conversations have any
+// number of participants, who can be added and removed.
+const (
+	KindDirect = "direct"
+	KindGroup  = "group"
+)
+
+// Limits on conversations
+const (
+	MaxTitleLength  = 100
+	MaxParticipants = 50
+)
+
+// Conversation represents a private conversation between two or more users
+type Conversation struct {
+	ID             string         `json:"id"`
+	Kind           string         `json:"kind"`
+	Title          string         `json:"title"`
+	CreatedBy      *string        `json:"created_by,omitempty"`
+	Participants   []*Participant `json:"participants"`
+	UnreadCount    int            `json:"unread_count"` // For the user who loaded the conversation
+	LastActivityAt time.Time      `json:"last_activity_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	directKey *string // Identifies the pair of users of a direct conversation
+}
+
+// NewConversation creates a new conversation created by the given user
+// between the participants, who include the creator
+func NewConversation(kind, title, createdBy string, participantIDs []string) *Conversation {
+	now := time.Now()
+	conversation := &Conversation{
+		Kind:           kind,
+		Title:          title,
+		CreatedBy:      &createdBy,
+		LastActivityAt: now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for _, userID := range participantIDs {
+		conversation.Participants = append(conversation.Participants, &Participant{
+			UserID:   userID,
+			JoinedAt: now,
+		})
+	}
+	if kind == KindDirect && len(participantIDs) == 2 {
+		key := directKey(participantIDs[0], participantIDs[1])
+		conversation.directKey = &key
+	}
+	return conversation
+}
+
+// IsDirect reports whether the conversation is a direct conversation
+func (c *Conversation) IsDirect() bool {
+	return c.Kind == KindDirect
+}
+
+// IsCreator reports whether the user created the conversation
+func (c *Conversation) IsCreator(userID string) bool {
+	return c.CreatedBy != nil && *c.CreatedBy == userID
+}
+
+// directKey returns the key shared by every direct conversation between the
+// two users, whichever of them started it
+func directKey(a, b string) string {
+	users := []string{a, b}
+	sort.Strings(users)
+	return users[0] + ":" + users[1]
+}
+
+// Participant is a user taking part in a conversation. The last-read pointer
+// is the newest message the user has read; messages from others after it are
+// unread.
+type Participant struct {
+	ConversationID    string     `json:"conversation_id"`
+	UserID            string     `json:"user_id"`
+	LastReadMessageID *string    `json:"last_read_message_id,omitempty"`
+	LastReadMessageAt *time.Time `json:"last_read_message_at,omitempty"`
+	JoinedAt          time.Time  `json:"joined_at"`
+}
+
+// Message represents a message sent to a conversation
+type Message struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	UserID         string    `json:"user_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NewMessage creates a new message from the user to a conversation
+func NewMessage(conversationID, userID, content string) *Message {
+	return &Message{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Content:        content,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// Page size limits for listing conversations and messages
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Page is one page of a user's conversations, most recently active first.
+// NextCursor is empty on the last page.
+type Page struct {
+	Conversations []*Conversation
+	NextCursor    string
+}
+
+// MessagePage is one page of a conversation's messages, newest first.
+// NextCursor is empty on the last page.
+type MessagePage struct {
+	Messages   []*Message
+	NextCursor string
+}
+
+// timeCursor is the keyset position after the last item of a page, made of
+// the time the listing is sorted by and the item ID
+type timeCursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// encodeCursor returns the opaque cursor for a keyset position
+func encodeCursor(t time.Time, id string) string {
+	data, _ := json.Marshal(timeCursor{Time: t, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor
+func decodeCursor(s string) (*timeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &timeCursor{}
+	if err := json.Unmarshal(data, c); err != nil || validator.ValidateUUID(c.ID) != nil || c.Time.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
\ No newline at end of file
@@ -0,0 +1,395 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/core/unread"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrConversationExists   = errors.New("direct conversation already exists")
+	ErrNotParticipant       = errors.New("not a participant of the conversation")
+	ErrMessageNotFound      = errors.New("message not found")
+	ErrTooManyParticipants  = errors.New("conversation has too many participants")
+	ErrInvalidCursor        = errors.New("invalid pagination cursor")
+)
+
+// conversationColumns lists the columns read by scanConversation, in order,
+// for a query on conversations aliased as c joined with the participation
+// of the user loading them, aliased as p
+var conversationColumns = `c.id, c.kind, c.title, c.created_by, c.last_activity_at, c.created_at, c.updated_at,
+	` + unread.CountQuery(unread.KindConversation, "p")
+
+// conversationSource joins conversations with the participation of the user
+// given as $1, so only their own conversations are found
+const conversationSource = ` FROM conversations c
+	JOIN conversation_participants p ON p.conversation_id = c.id AND p.user_id = $1`
+
+// Repository provides access to conversation storage
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new conversation repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Create inserts a new conversation into the database together with its
+// participants. It returns ErrConversationExists when a direct conversation
+// between the same users already exists.
+func (r *Repository) Create(ctx context.Context, conversation *Conversation) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Insert conversation
+	err = tx.QueryRow(ctx, `
+		INSERT INTO conversations (kind, title, direct_key, created_by, last_activity_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (direct_key) DO NOTHING
+		RETURNING id
+	`,
+		conversation.Kind,
+		conversation.Title,
+		conversation.directKey,
+		conversation.CreatedBy,
+		conversation.LastActivityAt,
+		conversation.CreatedAt,
+		conversation.UpdatedAt,
+	).Scan(&conversation.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrConversationExists
+		}
+		return err
+	}
+
+	// Add participants
+	for _, participant := range conversation.Participants {
+		participant.ConversationID = conversation.ID
+		_, err = tx.Exec(ctx, `
+			INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+			VALUES ($1, $2, $3)
+		`, participant.ConversationID, participant.UserID, participant.JoinedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID retrieves a conversation the user takes part in by ID
+func (r *Repository) GetByID(ctx context.Context, id, userID string) (*Conversation, error) {
+	query := "SELECT " + conversationColumns + conversationSource + " WHERE c.id = $2"
+	return r.get(ctx, query, userID, id)
+}
+
+// GetDirect retrieves the direct conversation between the user and another
+// user
+func (r *Repository) GetDirect(ctx context.Context, userID, otherID string) (*Conversation, error) {
+	query := "SELECT " + conversationColumns + conversationSource + " WHERE c.direct_key = $2"
+	return r.get(ctx, query, userID, directKey(userID, otherID))
+}
+
+// get retrieves a single conversation with its participants
+func (r *Repository) get(ctx context.Context, query string, args ...interface{}) (*Conversation, error) {
+	conversation, err := scanConversation(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+
+	if err := r.loadParticipants(ctx, []*Conversation{conversation}); err != nil {
+		return nil, err
+	}
+
+	return conversation, nil
+}
+
+// List retrieves up to limit conversations the user takes part in, most
+// recently active first and starting after the cursor position
+func (r *Repository) List(ctx context.Context, userID string, after *timeCursor, limit int) ([]*Conversation, error) {
+	query := "SELECT " + conversationColumns + conversationSource + `
+		WHERE $2::timestamptz IS NULL OR (c.last_activity_at, c.id) < ($2::timestamptz, $3::uuid)
+		ORDER BY c.last_activity_at DESC, c.id DESC
+		LIMIT $4
+	`
+
+	var afterTime *time.Time
+	var afterID *string
+	if after != nil {
+		afterTime, afterID = &after.Time, &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, query, userID, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []*Conversation
+	for rows.Next() {
+		conversation, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadParticipants(ctx, conversations); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+// AddParticipants adds users to a conversation. Users already taking part
+// are left as they are.
+func (r *Repository) AddParticipants(ctx context.Context, id string, userIDs []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the conversation so additions at the same time are counted in turn
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM conversations WHERE id = $1 FOR UPDATE", id); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+		SELECT $1, unnest($2::uuid[]), $3
+		ON CONFLICT (conversation_id, user_id) DO NOTHING
+	`, id, userIDs, now)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM conversation_participants WHERE conversation_id = $1", id).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > MaxParticipants {
+		return ErrTooManyParticipants
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE conversations SET updated_at = $2 WHERE id = $1", id, now); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveParticipant removes a user from a conversation. A conversation is
+// deleted once its last participant is gone.
+func (r *Repository) RemoveParticipant(ctx context.Context, id, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, "DELETE FROM conversation_participants WHERE conversation_id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotParticipant
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE conversations SET updated_at = $2 WHERE id = $1", id, time.Now()); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM conversations
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM conversation_participants WHERE conversation_id = $1)
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateMessage inserts a new message, bumps the conversation's last
+// activity and marks the message as read by its sender
+func (r *Repository) CreateMessage(ctx context.Context, message *Message) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Insert message
+	err = tx.QueryRow(ctx, `
+		INSERT INTO conversation_messages (conversation_id, user_id, content, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`,
+		message.ConversationID,
+		message.UserID,
+		message.Content,
+		message.CreatedAt,
+	).Scan(&message.ID)
+	if err != nil {
+		return err
+	}
+
+	// Bump last activity
+	_, err = tx.Exec(ctx, `
+		UPDATE conversations SET last_activity_at = GREATEST(last_activity_at, $2)
+		WHERE id = $1
+	`, message.ConversationID, message.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// The sender has read their own message
+	_, err = tx.Exec(ctx, unread.MarkReadQuery(unread.KindConversation), message.ConversationID, message.UserID, message.ID, message.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListMessages retrieves up to limit messages of a conversation, newest
+// first and starting after the cursor position
+func (r *Repository) ListMessages(ctx context.Context, id string, after *timeCursor, limit int) ([]*Message, error) {
+	query := `
+		SELECT id, conversation_id, user_id, content, created_at
+		FROM conversation_messages
+		WHERE conversation_id = $1
+			AND ($2::timestamptz IS NULL OR (created_at, id) < ($2::timestamptz, $3::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`
+
+	var afterTime *time.Time
+	var afterID *string
+	if after != nil {
+		afterTime, afterID = &after.Time, &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, query, id, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		message := &Message{}
+		err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.UserID,
+			&message.Content,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// MarkRead moves the user's last-read pointer forward to a message of the
+// conversation: the given message, or else the newest message sent at or
+// before readAt. The pointer never moves back to an older message.
+func (r *Repository) MarkRead(ctx context.Context, id, userID, messageID string, readAt *time.Time) error {
+	err := unread.MarkRead(ctx, r.db, unread.ConversationTarget(id), userID, messageID, readAt)
+	if errors.Is(err, unread.ErrMessageNotFound) {
+		return ErrMessageNotFound
+	}
+	return err
+}
+
+// loadParticipants fills in the participants of the conversations
+func (r *Repository) loadParticipants(ctx context.Context, conversations []*Conversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(conversations))
+	byID := make(map[string]*Conversation, len(conversations))
+	for i, conversation := range conversations {
+		ids[i] = conversation.ID
+		byID[conversation.ID] = conversation
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT conversation_id, user_id, last_read_message_id, last_read_message_at, joined_at
+		FROM conversation_participants
+		WHERE conversation_id = ANY($1::uuid[])
+		ORDER BY joined_at, user_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		participant := &Participant{}
+		err := rows.Scan(
+			&participant.ConversationID,
+			&participant.UserID,
+			&participant.LastReadMessageID,
+			&participant.LastReadMessageAt,
+			&participant.JoinedAt,
+		)
+		if err != nil {
+			return err
+		}
+		conversation := byID[participant.ConversationID]
+		conversation.Participants = append(conversation.Participants, participant)
+	}
+
+	return rows.Err()
+}
+
+// scanConversation scans a row selected with conversationColumns
+func scanConversation(row pgx.Row) (*Conversation, error) {
+	conversation := &Conversation{}
+	err := row.Scan(
+		&conversation.ID,
+		&conversation.Kind,
+		&conversation.Title,
+		&conversation.CreatedBy,
+		&conversation.LastActivityAt,
+		&conversation.CreatedAt,
+		&conversation.UpdatedAt,
+		&conversation.UnreadCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
\ No newline at end of file
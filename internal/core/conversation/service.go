@@ -0,0 +1,323 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/unread"
+)
+
+var (
+	ErrInvalidKind        = errors.New("kind must be direct or group")
+	ErrInvalidTitle       = errors.New("title must be at most 100 characters and is only allowed on group conversations")
+	ErrNoParticipants     = errors.New("a conversation needs at least one other participant")
+	ErrInvalidDirect      = errors.New("a direct conversation needs exactly one other participant")
+	ErrDirectConversation = errors.New("participants of a direct conversation can't change")
+	ErrNotCreator         = errors.New("only the conversation creator can remove other participants")
+	ErrInvalidPageSize    = errors.New("limit must not be negative")
+)
+
+// UserGetter loads the users added to a conversation
+type UserGetter interface {
+	GetUserByID(ctx context.Context, id string) (*auth.User, error)
+}
+
+// UnreadCounters keeps participants' unread counters in step with the
+// conversation's messages, their read pointers and participation
+type UnreadCounters interface {
+	Posted(ctx context.Context, target unread.Target, authorID string)
+	Refresh(ctx context.Context, userID string, target unread.Target)
+	Forget(ctx context.Context, userID string, target unread.Target)
+}
+
+// Service provides conversation operations
+type Service struct {
+	repo     *Repository
+	users    UserGetter
+	counters UnreadCounters
+}
+
+// NewService creates a new conversation service
+func NewService(repo *Repository, users UserGetter, counters UnreadCounters) *Service {
+	return &Service{
+		repo:     repo,
+		users:    users,
+		counters: counters,
+	}
+}
+
+// Create starts a conversation between the user and the participants. The
+// kind defaults to direct for one other participant and group otherwise.
+// Starting a direct conversation with someone the user already has one with
+// returns the existing conversation, and created is false.
+func (s *Service) Create(ctx context.Context, userID string, participantIDs []string, kind, title string) (conversation *Conversation, created bool, err error) {
+	// Validate participants, kind and title
+	others := otherParticipants(userID, participantIDs)
+	if len(others) == 0 {
+		return nil, false, ErrNoParticipants
+	}
+	if len(others)+1 > MaxParticipants {
+		return nil, false, ErrTooManyParticipants
+	}
+	if kind == "" {
+		kind = KindGroup
+		if len(others) == 1 {
+			kind = KindDirect
+		}
+	}
+	if kind != KindDirect && kind != KindGroup {
+		return nil, false, ErrInvalidKind
+	}
+	title = strings.TrimSpace(title)
+	if utf8.RuneCountInString(title) > MaxTitleLength || (kind == KindDirect && title != "") {
+		return nil, false, ErrInvalidTitle
+	}
+	if kind == KindDirect && len(others) != 1 {
+		return nil, false, ErrInvalidDirect
+	}
+
+	// Reuse an existing direct conversation
+	if kind == KindDirect {
+		conversation, err := s.repo.GetDirect(ctx, userID, others[0])
+		if err == nil {
+			return conversation, false, nil
+		}
+		if !errors.Is(err, ErrConversationNotFound) {
+			return nil, false, err
+		}
+	}
+
+	// Every participant must exist
+	if err := s.checkUsers(ctx, others); err != nil {
+		return nil, false, err
+	}
+
+	// Create conversation
+	conversation = NewConversation(kind, title, userID, append([]string{userID}, others...))
+
+	// Save conversation to database
+	if err := s.repo.Create(ctx, conversation); err != nil {
+		// The same direct conversation was started concurrently
+		if errors.Is(err, ErrConversationExists) {
+			conversation, err := s.repo.GetDirect(ctx, userID, others[0])
+			return conversation, false, err
+		}
+		return nil, false, err
+	}
+
+	return conversation, true, nil
+}
+
+// Get retrieves a conversation the user takes part in, with their unread
+// count. Other users' conversations are not found.
+func (s *Service) Get(ctx context.Context, id, userID string) (*Conversation, error) {
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+// List retrieves a page of the user's conversations, most recently active
+// first. The limit defaults to DefaultPageSize and is capped at MaxPageSize.
+func (s *Service) List(ctx context.Context, userID string, limit int, cursor string) (*Page, error) {
+	// Validate options
+	limit, err := pageSize(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *timeCursor
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Fetch one extra conversation to know whether another page follows
+	conversations, err := s.repo.List(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Conversations: conversations}
+	if len(conversations) > limit {
+		page.Conversations = conversations[:limit]
+		last := page.Conversations[limit-1]
+		page.NextCursor = encodeCursor(last.LastActivityAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// AddParticipants adds users to a group conversation on behalf of one of its
+// participants
+func (s *Service) AddParticipants(ctx context.Context, id, userID string, participantIDs []string) (*Conversation, error) {
+	// Check access
+	conversation, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.IsDirect() {
+		return nil, ErrDirectConversation
+	}
+
+	// Every new participant must exist
+	others := otherParticipants(userID, participantIDs)
+	if len(others) == 0 {
+		return conversation, nil
+	}
+	if err := s.checkUsers(ctx, others); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddParticipants(ctx, id, others); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, id, userID)
+}
+
+// RemoveParticipant removes a participant from a group conversation. Any
+// participant can leave; only the creator can remove someone else.
+func (s *Service) RemoveParticipant(ctx context.Context, id, userID, participantID string) error {
+	// Check access
+	conversation, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if conversation.IsDirect() {
+		return ErrDirectConversation
+	}
+	if participantID != userID && !conversation.IsCreator(userID) {
+		return ErrNotCreator
+	}
+
+	if err := s.repo.RemoveParticipant(ctx, id, participantID); err != nil {
+		return err
+	}
+
+	s.counters.Forget(ctx, participantID, unread.ConversationTarget(id))
+
+	return nil
+}
+
+// Send sends a message from the user to a conversation they take part in
+func (s *Service) Send(ctx context.Context, id, userID, content string) (*Message, error) {
+	// Check access
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	// Create message
+	message := NewMessage(id, userID, content)
+
+	// Save message to database
+	if err := s.repo.CreateMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	// Sending moved the sender's last-read pointer to their message
+	target := unread.ConversationTarget(id)
+	s.counters.Posted(ctx, target, userID)
+	s.counters.Refresh(ctx, userID, target)
+
+	return message, nil
+}
+
+// ListMessages retrieves a page of a conversation's messages, newest first.
+// The limit defaults to DefaultPageSize and is capped at MaxPageSize.
+func (s *Service) ListMessages(ctx context.Context, id, userID string, limit int, cursor string) (*MessagePage, error) {
+	// Validate options
+	limit, err := pageSize(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *timeCursor
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Check access
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra message to know whether another page follows
+	messages, err := s.repo.ListMessages(ctx, id, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// MarkRead marks the messages of a conversation as read by the user, up to
+// and including the given message, or else up to readAt. Marking older
+// messages than already read changes nothing.
+func (s *Service) MarkRead(ctx context.Context, id, userID, messageID string, readAt *time.Time) (*Conversation, error) {
+	// Check access
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.MarkRead(ctx, id, userID, messageID, readAt); err != nil {
+		return nil, err
+	}
+
+	s.counters.Refresh(ctx, userID, unread.ConversationTarget(id))
+
+	return s.Get(ctx, id, userID)
+}
+
+// checkUsers returns an error unless every user exists
+func (s *Service) checkUsers(ctx context.Context, userIDs []string) error {
+	for _, userID := range userIDs {
+		if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// otherParticipants returns the distinct participants other than the user,
+// in the order given
+func otherParticipants(userID string, participantIDs []string) []string {
+	seen := map[string]bool{userID: true}
+	var others []string
+	for _, id := range participantIDs {
+		if !seen[id] {
+			seen[id] = true
+			others = append(others, id)
+		}
+	}
+	return others
+}
+
+// pageSize applies the default and maximum page size to a requested limit
+func pageSize(limit int) (int, error) {
+	if limit < 0 {
+		return 0, ErrInvalidPageSize
+	}
+	if limit == 0 {
+		return DefaultPageSize, nil
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize, nil
+	}
+	return limit, nil
+}
\ No newline at end of file
@@ -0,0 +1,234 @@
+package message
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/infrastructure/cache"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	messageCachePrefix = "messages:cache:message:"
+	pageCachePrefix    = "messages:cache:page:"
+
+	// generationKeyPrefix prefixes the keys holding the generation of a
+	// message or of a channel's pages. Cache keys include the generation, so
+	// starting a new one invalidates every entry of the previous one,
+	// including entries a load in flight stores after the write.
+	generationKeyPrefix = "messages:cache:generation:"
+)
+
+var tracer = otel.Tracer("github.com/ivmello/go-api-template/internal/core/message")
+
+// CachedRepository caches single messages and listing pages in Redis in
+// front of another store. Entries are keyed by the generation of their
+// message or channel and tagged with it. Writes start a new generation of
+// the entries they affect after they succeed, so a read that starts after a
+// write never sees the entries from before it.
+//
+// Redis failures are logged and reads fall through to the underlying store.
+type CachedRepository struct {
+	Store
+	client   *redis.Client
+	messages cache.Cache[*Message]
+	pages    cache.Cache[[]*Message]
+	ttl      time.Duration
+	logger   *slog.Logger
+}
+
+// NewCachedRepository creates a caching decorator around a store. Entries
+// expire ttl after they were loaded.
+func NewCachedRepository(store Store, client *redis.Client, ttl time.Duration, logger *slog.Logger) *CachedRepository {
+	return &CachedRepository{
+		Store:    store,
+		client:   client,
+		messages: cache.NewRedis[*Message](client, messageCachePrefix, cache.JSON),
+		pages:    cache.NewRedis[[]*Message](client, pageCachePrefix, cache.JSON),
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// GetByID retrieves a message by ID, from the cache when possible
+func (r *CachedRepository) GetByID(ctx context.Context, id string) (*Message, error) {
+	ctx, span := tracer.Start(ctx, "message.cache.GetByID")
+	defer span.End()
+
+	load := func(ctx context.Context) (*Message, error) {
+		return r.Store.GetByID(ctx, id)
+	}
+
+	generation, err := r.generation(ctx, messageTag(id))
+	if err != nil {
+		r.logger.Error("Failed to read cached message generation", "error", err, "message_id", id)
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		return load(ctx)
+	}
+
+	return readThrough(ctx, r, span, r.messages, id+":"+generation, []string{messageTag(id)}, load)
+}
+
+// List retrieves a page of messages, from the cache when possible
+func (r *CachedRepository) List(ctx context.Context, opts ListOptions, after *timeCursor, limit int) ([]*Message, error) {
+	ctx, span := tracer.Start(ctx, "message.cache.List")
+	defer span.End()
+
+	load := func(ctx context.Context) ([]*Message, error) {
+		return r.Store.List(ctx, opts, after, limit)
+	}
+
+	generation, err := r.generation(ctx, channelTag(opts.ChannelID))
+	if err != nil {
+		r.logger.Error("Failed to read cached message page generation", "error", err, "channel_id", opts.ChannelID)
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		return load(ctx)
+	}
+
+	key, err := pageKey(opts, after, limit, generation)
+	if err != nil {
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		return load(ctx)
+	}
+
+	return readThrough(ctx, r, span, r.pages, key, []string{channelTag(opts.ChannelID)}, load)
+}
+
+// Create saves a new message and invalidates the pages of its channel and
+// its parent, whose reply count changed
+func (r *CachedRepository) Create(ctx context.Context, message *Message) error {
+	if err := r.Store.Create(ctx, message); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, message.ChannelID, message.ParentID)
+	return nil
+}
+
+// Update saves the content of a message and invalidates it and the pages of
+// its channel
+func (r *CachedRepository) Update(ctx context.Context, message *Message, editedBy string) error {
+	if err := r.Store.Update(ctx, message, editedBy); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, message.ChannelID, &message.ID)
+	return nil
+}
+
+// Delete moves a message to the trash and invalidates it, its parent and the
+// pages of its channel
+func (r *CachedRepository) Delete(ctx context.Context, message *Message) error {
+	if err := r.Store.Delete(ctx, message); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, message.ChannelID, &message.ID, message.ParentID)
+	return nil
+}
+
+// Restore takes a message out of the trash and invalidates its parent and
+// the pages of its channel
+func (r *CachedRepository) Restore(ctx context.Context, message *Message) error {
+	if err := r.Store.Restore(ctx, message); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, message.ChannelID, &message.ID, message.ParentID)
+	return nil
+}
+
+// readThrough returns the cached value under key, or loads and caches it on
+// a miss, recording whether it was a hit on the span
+func readThrough[T any](ctx context.Context, r *CachedRepository, span trace.Span, c cache.Cache[T], key string, tags []string, load func(ctx context.Context) (T, error)) (T, error) {
+	value, err := c.Get(ctx, key)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	if err == nil {
+		return value, nil
+	}
+
+	if !errors.Is(err, cache.ErrMiss) {
+		r.logger.Error("Failed to read cached message entry", "error", err, "key", key)
+		return load(ctx)
+	}
+
+	return c.GetOrLoad(ctx, key, r.ttl, load, tags...)
+}
+
+// pageKey returns the cache key of a listing page in a generation of its
+// channel
+func pageKey(opts ListOptions, after *timeCursor, limit int, generation string) (string, error) {
+	// The cursor and limit are passed separately
+	opts.Cursor, opts.Limit = "", 0
+	data, err := json.Marshal(struct {
+		Options ListOptions
+		After   *timeCursor
+		Limit   int
+	}{opts, after, limit})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+
+	return opts.ChannelID + ":" + generation + ":" + hex.EncodeToString(sum[:16]), nil
+}
+
+// channelTag returns the tag of a channel's cached pages
+func channelTag(channelID string) string {
+	return "channel:" + channelID
+}
+
+// messageTag returns the tag of a cached message
+func messageTag(id string) string {
+	return "message:" + id
+}
+
+// generation returns the current generation of the entries with a tag, "0"
+// before the first write
+func (r *CachedRepository) generation(ctx context.Context, tag string) (string, error) {
+	generation, err := r.client.Get(ctx, generationKeyPrefix+tag).Result()
+	if errors.Is(err, redis.Nil) {
+		return "0", nil
+	}
+	return generation, err
+}
+
+// invalidate starts a new generation of the cached messages with the given
+// IDs and of every cached page of the channel, then drops the entries of
+// the previous one. Generations are never reused, and outlive the entries a
+// slow load may still store for the previous one.
+func (r *CachedRepository) invalidate(ctx context.Context, channelID string, messageIDs ...*string) {
+	var tags []string
+	for _, id := range messageIDs {
+		if id != nil {
+			tags = append(tags, messageTag(*id))
+		}
+	}
+
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range append(tags, channelTag(channelID)) {
+			pipe.Set(ctx, generationKeyPrefix+tag, generation, 2*r.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to start a new cached message generation", "error", err, "channel_id", channelID)
+	}
+
+	if err := r.messages.Invalidate(ctx, tags...); err != nil {
+		r.logger.Error("Failed to invalidate cached messages", "error", err, "channel_id", channelID)
+	}
+	if err := r.pages.Invalidate(ctx, channelTag(channelID)); err != nil {
+		r.logger.Error("Failed to invalidate cached message pages", "error", err, "channel_id", channelID)
+	}
+}
\ No newline at end of file
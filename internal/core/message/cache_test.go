@@ -0,0 +1,97 @@
+package message
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// slowStore serves a message whose content changes on update. Reads wait on
+// release when it is set.
+type slowStore struct {
+	Store
+	mu      sync.Mutex
+	content string
+	release chan struct{}
+	loads   int // Reads waiting on release
+}
+
+func (s *slowStore) GetByID(ctx context.Context, id string) (*Message, error) {
+	s.mu.Lock()
+	message := &Message{ID: id, ChannelID: "channel", Content: s.content}
+	release := s.release
+	if release != nil {
+		s.loads++
+	}
+	s.mu.Unlock()
+
+	if release != nil {
+		<-release
+	}
+	return message, nil
+}
+
+func (s *slowStore) Update(ctx context.Context, message *Message, editedBy string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.content = message.Content
+	return nil
+}
+
+// loading reports whether a read is waiting on release
+func (s *slowStore) loading() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads > 0
+}
+
+func TestCachedRepositoryDropsLoadsRacingAWrite(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := &slowStore{content: "old", release: make(chan struct{})}
+	repo := NewCachedRepository(store, client, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	// Start a load of the old content and update the message meanwhile
+	loaded := make(chan *Message)
+	go func() {
+		message, err := repo.GetByID(ctx, "message")
+		if err != nil {
+			t.Errorf("GetByID: %v", err)
+		}
+		loaded <- message
+	}()
+	for !store.loading() {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := repo.Update(ctx, &Message{ID: "message", ChannelID: "channel", Content: "new"}, "user"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	store.mu.Lock()
+	close(store.release)
+	store.release = nil
+	store.mu.Unlock()
+
+	if message := <-loaded; message.Content != "old" {
+		t.Fatalf("racing load returned %q, want old", message.Content)
+	}
+
+	// The racing load stored the old content, but reads after the write
+	// don't see it
+	message, err := repo.GetByID(ctx, "message")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if message.Content != "new" {
+		t.Errorf("content = %q after the update, want new", message.Content)
+	}
+}
\ No newline at end of file
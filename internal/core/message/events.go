@@ -0,0 +1,427 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// eventStreamKey is the Redis stream holding recent message events
+const eventStreamKey = "messages:events"
+
+const (
+	// eventReadBlock is how long a read waits for new events before polling
+	// again
+	eventReadBlock = 5 * time.Second
+
+	// eventBatchSize bounds the number of events read per round trip
+	eventBatchSize = 100
+
+	// subscriberBuffer is the number of live events a subscriber may fall
+	// behind before it is dropped
+	subscriberBuffer = 256
+
+	// publishTimeout bounds the append of an event, which outlives the
+	// request that made the change
+	publishTimeout = 5 * time.Second
+)
+
+var (
+	ErrInvalidEventID    = errors.New("invalid event ID")
+	ErrEventsExpired     = errors.New("events after the given ID are no longer retained")
+	ErrSubscriberLagged  = errors.New("subscriber fell too far behind the event stream")
+	ErrEventStreamClosed = errors.New("event stream closed")
+)
+
+// EventType is the kind of change a message event describes
+type EventType string
+
+// Message event types
+const (
+	EventCreated  EventType = "created"
+	EventUpdated  EventType = "updated"
+	EventDeleted  EventType = "deleted"
+	EventRestored EventType = "restored"
+)
+
+// Event is a change to a message. IDs are Redis stream IDs and increase with
+// every event, so the last seen ID can be used to resume a subscription.
+// Deleted messages carry no content.
+type Event struct {
+	ID         string    `json:"-"`
+	Type       EventType `json:"type"`
+	Message    *Message  `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventStream publishes message events to a capped Redis stream shared by
+// all instances, and fans them out to the subscribers of this instance
+type EventStream struct {
+	client *redis.Client
+	maxLen int64
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewEventStream creates a new event stream retaining about maxLen events
+func NewEventStream(client *redis.Client, maxLen int, logger *slog.Logger) *EventStream {
+	return &EventStream{
+		client:      client,
+		maxLen:      int64(maxLen),
+		logger:      logger,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish appends an event for a change to the message. The change is
+// already committed, so failures are logged rather than returned, and the
+// event is published even when the request is canceled.
+func (s *EventStream) Publish(ctx context.Context, eventType EventType, message *Message) {
+	data, err := json.Marshal(&Event{
+		Type:       eventType,
+		Message:    message,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to encode message event", "error", err, "message_id", message.ID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+
+	err = s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: eventStreamKey,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	}).Err()
+	if err != nil {
+		s.logger.Error("Failed to publish message event", "error", err, "type", eventType, "message_id", message.ID)
+	}
+}
+
+// Run reads new events from Redis and delivers them to subscribers until
+// the context is canceled, then closes every subscription
+func (s *EventStream) Run(ctx context.Context) error {
+	defer s.closeAll()
+
+	// Start after the newest event so nothing published from now on is missed
+	lastID := "0-0"
+	latest, err := s.client.XRevRangeN(ctx, eventStreamKey, "+", "-", 1).Result()
+	if err != nil && ctx.Err() == nil {
+		s.logger.Error("Failed to read latest message event", "error", err)
+	}
+	if len(latest) > 0 {
+		lastID = latest[0].ID
+	}
+
+	for {
+		streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{eventStreamKey, lastID},
+			Count:   eventBatchSize,
+			Block:   eventReadBlock,
+		}).Result()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			if !errors.Is(err, redis.Nil) {
+				s.logger.Error("Failed to read message events", "error", err)
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(time.Second):
+				}
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				lastID = entry.ID
+				event, err := decodeEvent(entry)
+				if err != nil {
+					s.logger.Error("Skipping malformed message event", "error", err, "id", entry.ID)
+					continue
+				}
+				s.broadcast(event)
+			}
+		}
+	}
+}
+
+// EventFilter decides whether an event is delivered to a subscriber. An
+// error ends the subscription.
+type EventFilter func(event *Event) (bool, error)
+
+// Subscribe returns a subscription to the message events passing the filter.
+// With an empty afterID only new events are delivered; otherwise every
+// retained event after it is replayed first. The subscription ends when the
+// context is canceled.
+func (s *EventStream) Subscribe(ctx context.Context, afterID string, filter EventFilter) (*Subscription, error) {
+	// The stream is trimmed from its oldest end, so events after afterID may
+	// be gone once the oldest retained event is newer than it
+	if afterID != "" {
+		if _, _, ok := parseEventID(afterID); !ok {
+			return nil, ErrInvalidEventID
+		}
+		oldest, err := s.client.XRangeN(ctx, eventStreamKey, "-", "+", 1).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(oldest) > 0 && compareEventIDs(oldest[0].ID, afterID) > 0 {
+			return nil, ErrEventsExpired
+		}
+	}
+
+	sub := &Subscription{
+		live:   make(chan *Event, subscriberBuffer),
+		events: make(chan *Event),
+	}
+
+	// Without a replay, register right away so no event published after
+	// Subscribe returns is missed. Replaying subscriptions register once
+	// they have caught up.
+	if afterID == "" {
+		if err := s.register(sub); err != nil {
+			return nil, err
+		}
+	} else if s.isClosed() {
+		return nil, ErrEventStreamClosed
+	}
+
+	go s.pump(ctx, sub, afterID, filter)
+
+	return sub, nil
+}
+
+// pump replays retained events after afterID, then forwards live events
+// passing the filter to the subscriber in order and without duplicates
+func (s *EventStream) pump(ctx context.Context, sub *Subscription, afterID string, filter EventFilter) {
+	defer close(sub.events)
+	defer s.unsubscribe(sub)
+
+	send := func(event *Event) bool {
+		deliver, err := filter(event)
+		if err != nil {
+			sub.err = err
+			return false
+		}
+		if !deliver {
+			afterID = event.ID
+			return true
+		}
+
+		select {
+		case sub.events <- event:
+			afterID = event.ID
+			return true
+		case <-ctx.Done():
+			sub.err = ctx.Err()
+			return false
+		}
+	}
+
+	// replay sends the retained events after afterID until it reaches the
+	// end of the stream
+	replay := func() bool {
+		for {
+			entries, err := s.client.XRangeN(ctx, eventStreamKey, "("+afterID, "+", eventBatchSize).Result()
+			if err != nil {
+				sub.err = err
+				return false
+			}
+			for _, entry := range entries {
+				event, err := decodeEvent(entry)
+				if err != nil {
+					afterID = entry.ID
+					continue
+				}
+				if !send(event) {
+					return false
+				}
+			}
+			if len(entries) < eventBatchSize {
+				return true
+			}
+		}
+	}
+
+	// Replay from Redis until caught up, however long that takes, and only
+	// then register for live events. The second replay covers the events
+	// published in between, so the live buffer only fills during that short
+	// read and live events already replayed are skipped below.
+	if afterID != "" {
+		if !replay() {
+			return
+		}
+		if err := s.register(sub); err != nil {
+			sub.err = err
+			return
+		}
+		if !replay() {
+			return
+		}
+	}
+
+	// Forward live events, skipping those already replayed
+	for {
+		select {
+		case event, ok := <-sub.live:
+			if !ok {
+				sub.err = sub.liveErr
+				return
+			}
+			if afterID != "" && compareEventIDs(event.ID, afterID) <= 0 {
+				continue
+			}
+			if !send(event) {
+				return
+			}
+		case <-ctx.Done():
+			sub.err = ctx.Err()
+			return
+		}
+	}
+}
+
+// register starts delivering live events to a subscriber
+func (s *EventStream) register(sub *Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrEventStreamClosed
+	}
+	s.subscribers[sub] = struct{}{}
+	return nil
+}
+
+// isClosed reports whether the stream stopped delivering events
+func (s *EventStream) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// broadcast delivers an event to every subscriber, dropping those whose
+// buffer is full
+func (s *EventStream) broadcast(event *Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		select {
+		case sub.live <- event:
+		default:
+			delete(s.subscribers, sub)
+			sub.liveErr = ErrSubscriberLagged
+			close(sub.live)
+		}
+	}
+}
+
+// unsubscribe stops delivering events to a subscriber
+func (s *EventStream) unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.live)
+	}
+}
+
+// closeAll ends every subscription when the stream stops
+func (s *EventStream) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		sub.liveErr = ErrEventStreamClosed
+		close(sub.live)
+	}
+}
+
+// Subscription is a stream of message events for one subscriber
+type Subscription struct {
+	live    chan *Event // Fed by the event stream
+	liveErr error       // Why live was closed, set before closing
+	events  chan *Event // Read by the subscriber
+	err     error       // Why events was closed, set before closing
+}
+
+// Events returns the channel of events. It is closed when the subscription
+// ends, after which Err reports why.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Err returns the reason the subscription ended: ErrSubscriberLagged,
+// ErrEventStreamClosed, a filter error or the context error. It must only be called after
+// the events channel is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// decodeEvent parses a stream entry written by Publish
+func decodeEvent(entry redis.XMessage) (*Event, error) {
+	data, ok := entry.Values["event"].(string)
+	if !ok {
+		return nil, errors.New("missing event field")
+	}
+
+	event := &Event{}
+	if err := json.Unmarshal([]byte(data), event); err != nil {
+		return nil, err
+	}
+	if event.Message == nil {
+		return nil, errors.New("missing message")
+	}
+	event.ID = entry.ID
+	return event, nil
+}
+
+// parseEventID splits a stream ID of the form <milliseconds>-<sequence>
+func parseEventID(id string) (uint64, uint64, bool) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// compareEventIDs orders two stream IDs, treating malformed IDs as zero
+func compareEventIDs(a, b string) int {
+	aMs, aSeq, _ := parseEventID(a)
+	bMs, bSeq, _ := parseEventID(b)
+	switch {
+	case aMs < bMs || (aMs == bMs && aSeq < bSeq):
+		return -1
+	case aMs > bMs || (aMs == bMs && aSeq > bSeq):
+		return 1
+	}
+	return 0
+}
\ No newline at end of file
@@ -0,0 +1,109 @@
+package message
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestEventStream(t *testing.T, maxLen int) (*EventStream, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewEventStream(client, maxLen, slog.New(slog.NewTextHandler(io.Discard, nil))), client
+}
+
+// publish appends count created events and returns their IDs
+func publish(t *testing.T, stream *EventStream, client *redis.Client, count int) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	for i := 0; i < count; i++ {
+		stream.Publish(ctx, EventCreated, &Message{ID: strconv.Itoa(i)})
+	}
+
+	entries, err := client.XRange(ctx, eventStreamKey, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange: %v", err)
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+// nextEvent waits for the next event of the subscription
+func nextEvent(t *testing.T, sub *Subscription) *Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			t.Fatalf("subscription ended: %v", sub.Err())
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+	return nil
+}
+
+func deliverAll(event *Event) (bool, error) {
+	return true, nil
+}
+
+func TestSubscribeRejectsTrimmedEvents(t *testing.T) {
+	stream, client := newTestEventStream(t, 5)
+	ids := publish(t, stream, client, 20)
+	ctx := context.Background()
+
+	if _, err := stream.Subscribe(ctx, "0-1", deliverAll); err != ErrEventsExpired {
+		t.Errorf("Subscribe after a trimmed event = %v, want ErrEventsExpired", err)
+	}
+	if _, err := stream.Subscribe(ctx, ids[0], deliverAll); err != nil {
+		t.Errorf("Subscribe after the oldest retained event: %v", err)
+	}
+	if _, err := stream.Subscribe(ctx, "latest", deliverAll); err != ErrInvalidEventID {
+		t.Errorf("Subscribe after a malformed ID = %v, want ErrInvalidEventID", err)
+	}
+}
+
+func TestSubscribeReplaysPastTheLiveBuffer(t *testing.T) {
+	stream, client := newTestEventStream(t, 10000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ids := publish(t, stream, client, 2*eventBatchSize)
+	go stream.Run(ctx)
+
+	sub, err := stream.Subscribe(ctx, ids[0], deliverAll)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	// Publish more live events than the buffer holds before reading any
+	ids = publish(t, stream, client, 2*subscriberBuffer)
+	time.Sleep(100 * time.Millisecond)
+
+	for _, want := range ids[1:] {
+		if event := nextEvent(t, sub); event.ID != want {
+			t.Fatalf("event %s, want %s", event.ID, want)
+		}
+	}
+
+	// The subscriber wasn't dropped and keeps receiving live events
+	ids = publish(t, stream, client, 1)
+	if event := nextEvent(t, sub); event.ID != ids[len(ids)-1] {
+		t.Fatalf("live event %s, want %s", event.ID, ids[len(ids)-1])
+	}
+}
\ No newline at end of file
@@ -1,25 +1,203 @@
 package message
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"time"
+
+	"github.com/ivmello/go-api-template/pkg/diff"
+	"github.com/ivmello/go-api-template/pkg/validator"
 )
 
 // Message represents a message in the system
 type Message struct {
+	ID            string     `json:"id"`
+	ChannelID     string     `json:"channel_id"`
+	UserID        string     `json:"user_id"`
+	ParentID      *string    `json:"parent_id,omitempty"`
+	Content       string     `json:"content"`
+	RevisionCount int        `json:"revision_count"`
+	ReplyCount    int        `json:"reply_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+}
+
+// NewMessage creates a new message in a channel. An empty parentID starts a
+// new thread.
+func NewMessage(channelID, userID, content, parentID string) *Message {
+	now := time.Now()
+	message := &Message{
+		ChannelID:     channelID,
+		UserID:        userID,
+		Content:       content,
+		RevisionCount: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if parentID != "" {
+		message.ParentID = &parentID
+	}
+	return message
+}
+
+// IsEdited reports whether the message was changed after it was created
+func (m *Message) IsEdited() bool {
+	return m.RevisionCount > 1
+}
+
+// IsDeleted reports whether the message is in the trash. Deleted messages
+// that have replies appear in threads as tombstones.
+func (m *Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// MaxThreadSize bounds the number of messages returned for a thread
+const MaxThreadSize = 1000
+
+// ThreadNode is a message within a thread and its replies, oldest first.
+// Depth is 0 for the message the thread was fetched from.
+type ThreadNode struct {
+	Message *Message
+	Depth   int
+	Replies []*ThreadNode
+}
+
+// Thread is a message and its replies at any depth. Truncated is set when the
+// thread had more than MaxThreadSize messages and the deepest were left out.
+type Thread struct {
+	Root      *ThreadNode
+	Truncated bool
+}
+
+// Flatten returns the thread's messages in reading order: each message is
+// followed by its replies.
+func (t *Thread) Flatten() []*ThreadNode {
+	var nodes []*ThreadNode
+	var walk func(node *ThreadNode)
+	walk = func(node *ThreadNode) {
+		nodes = append(nodes, node)
+		for _, reply := range node.Replies {
+			walk(reply)
+		}
+	}
+	walk(t.Root)
+	return nodes
+}
+
+// Revision is the content of a message as of one edit. Revision 1 is the
+// original content.
+type Revision struct {
 	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
+	MessageID string    `json:"message_id"`
+	Revision  int       `json:"revision"`
 	Content   string    `json:"content"`
+	EditedBy  *string   `json:"edited_by,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// NewMessage creates a new message
-func NewMessage(userID, content string) *Message {
-	now := time.Now()
-	return &Message{
-		UserID:    userID,
-		Content:   content,
-		CreatedAt: now,
-		UpdatedAt: now,
+// RevisionDiff is the line-level difference between two revisions
+type RevisionDiff struct {
+	From  *Revision
+	To    *Revision
+	Lines []diff.Line
+}
+
+// Sort orders for listing messages
+const (
+	SortDesc = "desc"
+	SortAsc  = "asc"
+)
+
+// Page size limits for listing messages
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ListOptions filters, sorts and paginates a message listing
+type ListOptions struct {
+	ChannelID     string
+	UserID        string
+	CreatedAfter  *time.Time // Inclusive
+	CreatedBefore *time.Time // Exclusive
+	Order         string
+	Limit         int
+	Cursor        string
+}
+
+// Page is one page of a message listing. NextCursor is empty on the last page.
+type Page struct {
+	Messages   []*Message
+	NextCursor string
+}
+
+// timeCursor is the keyset position after the last message of a page, made of
+// the time the listing is sorted by and the message ID
+type timeCursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// encodeCursor returns the opaque cursor for a keyset position
+func encodeCursor(t time.Time, id string) string {
+	data, _ := json.Marshal(timeCursor{Time: t, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor
+func decodeCursor(s string) (*timeCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &timeCursor{}
+	if err := json.Unmarshal(data, c); err != nil || validator.ValidateUUID(c.ID) != nil || c.Time.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// MaxSearchQueryLength bounds the length of a full-text search query
+const MaxSearchQueryLength = 256
+
+// SearchResult is a message matching a full-text search
+type SearchResult struct {
+	Message *Message
+	Rank    float32
+	Snippet string // Content excerpt with matches wrapped in <mark> tags
+}
+
+// SearchPage is one page of search results, best match first. NextCursor is
+// empty on the last page.
+type SearchPage struct {
+	Results    []*SearchResult
+	NextCursor string
+}
+
+// searchCursor is the keyset position after the last result of a search page
+type searchCursor struct {
+	Rank float32 `json:"r"`
+	ID   string  `json:"id"`
+}
+
+// encodeSearchCursor returns the opaque cursor pointing after the result
+func encodeSearchCursor(result *SearchResult) string {
+	data, _ := json.Marshal(searchCursor{Rank: result.Rank, ID: result.Message.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSearchCursor parses an opaque search cursor
+func decodeSearchCursor(s string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &searchCursor{}
+	if err := json.Unmarshal(data, c); err != nil || validator.ValidateUUID(c.ID) != nil {
+		return nil, ErrInvalidCursor
 	}
+	return c, nil
 }
\ No newline at end of file
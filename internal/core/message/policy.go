@@ -0,0 +1,11 @@
+package message
+
+import (
+	"github.com/ivmello/go-api-template/pkg/auth"
+)
+
+// CanModify reports whether a user may edit or delete a message.
+// Authors may modify their own messages; moderators and admins may modify any.
+func CanModify(message *Message, userID string, roles []string) bool {
+	return message.UserID == userID || auth.HasPermission(roles, auth.PermMessagesModerate)
+}
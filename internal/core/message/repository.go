@@ -3,16 +3,24 @@ package message
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
-	ErrMessageNotFound = errors.New("message not found")
-	ErrForbidden       = errors.New("access forbidden")
+	ErrMessageNotFound  = errors.New("message not found")
+	ErrParentNotFound   = errors.New("parent message not found")
+	ErrInvalidCursor    = errors.New("invalid pagination cursor")
+	ErrRevisionNotFound = errors.New("message revision not found")
 )
 
+// messageColumns lists the columns read by scanMessage, in order
+const messageColumns = "id, channel_id, user_id, parent_id, content, revision_count, reply_count, created_at, updated_at, deleted_at"
+
 // Repository provides access to message storage
 type Repository struct {
 	db *pgxpool.Pool
@@ -25,142 +33,518 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 	}
 }
 
-// Create inserts a new message into the database
+// Create inserts a new message into the database together with its first
+// revision. Replies bump the reply count of their parent, which must be in
+// the same channel and not deleted.
 func (r *Repository) Create(ctx context.Context, message *Message) error {
-	query := `
-		INSERT INTO messages (user_id, content, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Count the reply on its parent
+	if message.ParentID != nil {
+		result, err := tx.Exec(ctx,
+			"UPDATE messages SET reply_count = reply_count + 1 WHERE id = $1 AND channel_id = $2 AND deleted_at IS NULL",
+			*message.ParentID, message.ChannelID,
+		)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrParentNotFound
+		}
+	}
+
+	// Insert message
+	err = tx.QueryRow(ctx, `
+		INSERT INTO messages (channel_id, user_id, parent_id, content, revision_count, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
-	`
-	
-	return r.db.QueryRow(ctx, query,
+	`,
+		message.ChannelID,
 		message.UserID,
+		message.ParentID,
 		message.Content,
+		message.RevisionCount,
 		message.CreatedAt,
 		message.UpdatedAt,
 	).Scan(&message.ID)
+	if err != nil {
+		return err
+	}
+
+	// Record the original content
+	_, err = tx.Exec(ctx, `
+		INSERT INTO message_revisions (message_id, revision, content, edited_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, message.ID, message.RevisionCount, message.Content, message.UserID, message.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// GetAll retrieves all messages
-func (r *Repository) GetAll(ctx context.Context) ([]*Message, error) {
-	query := `
-		SELECT id, user_id, content, created_at, updated_at
-		FROM messages
-		ORDER BY created_at DESC
+// List retrieves up to limit messages matching the options, starting after
+// the cursor position. Messages are ordered by (created_at, id) so pages stay
+// stable while new messages are written.
+func (r *Repository) List(ctx context.Context, opts ListOptions, after *timeCursor, limit int) ([]*Message, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	// addArg appends a query argument and returns its placeholder
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Filters
+	if opts.ChannelID != "" {
+		conditions = append(conditions, "channel_id = "+addArg(opts.ChannelID))
+	}
+	if opts.UserID != "" {
+		conditions = append(conditions, "user_id = "+addArg(opts.UserID))
+	}
+	if opts.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+addArg(*opts.CreatedAfter))
+	}
+	if opts.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+addArg(*opts.CreatedBefore))
+	}
+
+	// Keyset pagination
+	direction, comparison := "DESC", "<"
+	if opts.Order == SortAsc {
+		direction, comparison = "ASC", ">"
+	}
+	if after != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) %s (%s, %s)", comparison, addArg(after.Time), addArg(after.ID)))
+	}
+
+	query := "SELECT " + messageColumns + " FROM messages WHERE " + strings.Join(conditions, " AND ")
+	query += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT %s", direction, direction, addArg(limit))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectMessages(rows)
+}
+
+// Search runs a ranked full-text search over the content of messages in the
+// given channels, returning up to limit results after the cursor position.
+// Snippets are only built for the returned page.
+func (r *Repository) Search(ctx context.Context, language, query string, channelIDs []string, after *searchCursor, limit int) ([]*SearchResult, error) {
+	var afterRank *float32
+	var afterID *string
+	if after != nil {
+		afterRank, afterID = &after.Rank, &after.ID
+	}
+
+	sql := `
+		SELECT ` + messageColumns + `, rank,
+			ts_headline($1::regconfig, content, query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')
+		FROM (
+			SELECT m.id, q.query, ts_rank(m.search_vector, q.query) AS rank
+			FROM messages m, websearch_to_tsquery($1::regconfig, $2) AS q(query)
+			WHERE m.search_vector @@ q.query AND m.deleted_at IS NULL
+				AND m.channel_id = ANY($6::uuid[])
+				AND ($3::real IS NULL OR (ts_rank(m.search_vector, q.query), m.id) < ($3::real, $4::uuid))
+			ORDER BY rank DESC, m.id DESC
+			LIMIT $5
+		) AS results
+		JOIN messages USING (id)
+		ORDER BY rank DESC, id DESC
 	`
-	
-	rows, err := r.db.Query(ctx, query)
+
+	rows, err := r.db.Query(ctx, sql, language, query, afterRank, afterID, limit, channelIDs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	
-	var messages []*Message
+
+	var results []*SearchResult
 	for rows.Next() {
-		message := &Message{}
-		err := rows.Scan(
-			&message.ID,
-			&message.UserID,
-			&message.Content,
-			&message.CreatedAt,
-			&message.UpdatedAt,
-		)
+		result := &SearchResult{}
+		message, err := scanMessage(rows, &result.Rank, &result.Snippet)
 		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, message)
+		result.Message = message
+		results = append(results, result)
 	}
-	
+
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	
-	return messages, nil
+
+	return results, nil
 }
 
-// GetByID retrieves a message by ID
+// CheckSearchLanguage fails when language isn't a text search configuration
+// known to the database
+func CheckSearchLanguage(ctx context.Context, db *pgxpool.Pool, language string) error {
+	if _, err := db.Exec(ctx, "SELECT $1::regconfig", language); err != nil {
+		return fmt.Errorf("SEARCH_LANGUAGE %q is not a Postgres text search configuration: %w", language, err)
+	}
+	return nil
+}
+
+// GetByID retrieves a message by ID. Deleted messages are not found.
 func (r *Repository) GetByID(ctx context.Context, id string) (*Message, error) {
-	message := &Message{}
-	
 	query := `
-		SELECT id, user_id, content, created_at, updated_at
+		SELECT ` + messageColumns + `
 		FROM messages
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-	
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&message.ID,
-		&message.UserID,
-		&message.Content,
-		&message.CreatedAt,
-		&message.UpdatedAt,
-	)
-	
+
+	message, err := scanMessage(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrMessageNotFound
 		}
 		return nil, err
 	}
-	
+
 	return message, nil
 }
 
-// Update updates a message
-func (r *Repository) Update(ctx context.Context, id, userID, content string) error {
-	// Check if message exists and belongs to the user
-	var ownerID string
-	err := r.db.QueryRow(ctx, 
-		"SELECT user_id FROM messages WHERE id = $1", 
-		id,
-	).Scan(&ownerID)
-	
+// Update saves the content of a message and records it as a new revision in
+// the same transaction. The message's revision count and update time are
+// refreshed.
+func (r *Repository) Update(ctx context.Context, message *Message, editedBy string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Update message
+	err = tx.QueryRow(ctx, `
+		UPDATE messages
+		SET content = $1, revision_count = revision_count + 1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING revision_count, updated_at
+	`, message.Content, message.ID).Scan(&message.RevisionCount, &message.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrMessageNotFound
 		}
 		return err
 	}
-	
-	// Check ownership
-	if ownerID != userID {
-		return ErrForbidden
+
+	// Record revision
+	_, err = tx.Exec(ctx, `
+		INSERT INTO message_revisions (message_id, revision, content, edited_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, message.ID, message.RevisionCount, message.Content, editedBy, message.UpdatedAt)
+	if err != nil {
+		return err
 	}
-	
-	// Update message
+
+	return tx.Commit(ctx)
+}
+
+// ListRevisions retrieves every revision of a message, oldest first
+func (r *Repository) ListRevisions(ctx context.Context, messageID string) ([]*Revision, error) {
 	query := `
-		UPDATE messages
-		SET content = $1, updated_at = NOW()
-		WHERE id = $2
+		SELECT id, message_id, revision, content, edited_by, created_at
+		FROM message_revisions
+		WHERE message_id = $1
+		ORDER BY revision
+	`
+
+	rows, err := r.db.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*Revision
+	for rows.Next() {
+		revision := &Revision{}
+		err := rows.Scan(
+			&revision.ID,
+			&revision.MessageID,
+			&revision.Revision,
+			&revision.Content,
+			&revision.EditedBy,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves a single revision of a message
+func (r *Repository) GetRevision(ctx context.Context, messageID string, number int) (*Revision, error) {
+	revision := &Revision{}
+
+	query := `
+		SELECT id, message_id, revision, content, edited_by, created_at
+		FROM message_revisions
+		WHERE message_id = $1 AND revision = $2
+	`
+
+	err := r.db.QueryRow(ctx, query, messageID, number).Scan(
+		&revision.ID,
+		&revision.MessageID,
+		&revision.Revision,
+		&revision.Content,
+		&revision.EditedBy,
+		&revision.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// GetDeletedByID retrieves a message that was moved to the trash after the
+// cutoff by ID
+func (r *Repository) GetDeletedByID(ctx context.Context, id string, cutoff time.Time) (*Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE id = $1 AND deleted_at >= $2
+	`
+
+	message, err := scanMessage(r.db.QueryRow(ctx, query, id, cutoff))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// ListDeleted retrieves up to limit messages from a user's trash that were
+// deleted after the cutoff, most recently deleted first, starting after the
+// cursor position
+func (r *Repository) ListDeleted(ctx context.Context, userID string, cutoff time.Time, after *timeCursor, limit int) ([]*Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE user_id = $1 AND deleted_at >= $2
+			AND ($3::timestamptz IS NULL OR (deleted_at, id) < ($3::timestamptz, $4::uuid))
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $5
+	`
+
+	var afterTime *time.Time
+	var afterID *string
+	if after != nil {
+		afterTime, afterID = &after.Time, &after.ID
+	}
+
+	rows, err := r.db.Query(ctx, query, userID, cutoff, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectMessages(rows)
+}
+
+// GetThread retrieves a message and up to limit of its replies at any depth,
+// including deleted ones, ordered by depth then creation time
+func (r *Repository) GetThread(ctx context.Context, id string, limit int) ([]*ThreadNode, error) {
+	query := `
+		WITH RECURSIVE thread AS (
+			SELECT ` + messageColumns + `, 0 AS depth
+			FROM messages
+			WHERE id = $1
+			UNION ALL
+			SELECT m.id, m.channel_id, m.user_id, m.parent_id, m.content, m.revision_count, m.reply_count,
+				m.created_at, m.updated_at, m.deleted_at, t.depth + 1
+			FROM messages m
+			JOIN thread t ON m.parent_id = t.id
+		)
+		SELECT ` + messageColumns + `, depth
+		FROM thread
+		ORDER BY depth, created_at, id
+		LIMIT $2
 	`
-	
-	_, err = r.db.Exec(ctx, query, content, id)
-	return err
+
+	rows, err := r.db.Query(ctx, query, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nodes []*ThreadNode
+	for rows.Next() {
+		node := &ThreadNode{}
+		message, err := scanMessage(rows, &node.Depth)
+		if err != nil {
+			return nil, err
+		}
+		node.Message = message
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// Delete moves a message to the trash. Its replies are kept.
+func (r *Repository) Delete(ctx context.Context, message *Message) error {
+	return r.setDeleted(ctx, message.ID, true)
+}
+
+// Restore takes a message out of the trash
+func (r *Repository) Restore(ctx context.Context, message *Message) error {
+	return r.setDeleted(ctx, message.ID, false)
 }
 
-// Delete deletes a message
-func (r *Repository) Delete(ctx context.Context, id, userID string) error {
-	// Check if message exists and belongs to the user
-	var ownerID string
-	err := r.db.QueryRow(ctx, 
-		"SELECT user_id FROM messages WHERE id = $1", 
-		id,
-	).Scan(&ownerID)
-	
+// setDeleted moves a message into or out of the trash and keeps the reply
+// count of its parent in step
+func (r *Repository) setDeleted(ctx context.Context, id string, deleted bool) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := "UPDATE messages SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING parent_id"
+	delta := -1
+	if !deleted {
+		query = "UPDATE messages SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING parent_id"
+		delta = 1
+	}
+
+	var parentID *string
+	if err := tx.QueryRow(ctx, query, id).Scan(&parentID); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrMessageNotFound
 		}
 		return err
 	}
-	
-	// Check ownership
-	if ownerID != userID {
-		return ErrForbidden
+
+	if parentID != nil {
+		_, err := tx.Exec(ctx, "UPDATE messages SET reply_count = reply_count + $1 WHERE id = $2", delta, *parentID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// PurgeDeleted permanently deletes up to limit messages that were moved to
+// the trash before the cutoff and returns how many were deleted. Messages
+// that still have replies are left for EraseDeleted.
+func (r *Repository) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM messages
+		WHERE id IN (
+			SELECT m.id FROM messages m
+			WHERE m.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM messages r WHERE r.parent_id = m.id)
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.Exec(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// EraseDeleted clears the content and edit history of messages that were
+// moved to the trash before the cutoff but are kept as tombstones because
+// they have replies. It returns how many were erased.
+func (r *Repository) EraseDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM message_revisions
+		WHERE message_id IN (SELECT id FROM messages WHERE deleted_at < $1)
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx,
+		"UPDATE messages SET content = '' WHERE deleted_at < $1 AND content <> ''",
+		cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
 	}
-	
-	// Delete message
-	query := "DELETE FROM messages WHERE id = $1"
-	_, err = r.db.Exec(ctx, query, id)
-	return err
+
+	return result.RowsAffected(), nil
+}
+
+// scanMessage scans a row selected with messageColumns, followed by any
+// extra columns
+func scanMessage(row pgx.Row, extra ...interface{}) (*Message, error) {
+	message := &Message{}
+	dest := append([]interface{}{
+		&message.ID,
+		&message.ChannelID,
+		&message.UserID,
+		&message.ParentID,
+		&message.Content,
+		&message.RevisionCount,
+		&message.ReplyCount,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+		&message.DeletedAt,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// collectMessages scans and closes rows selected with messageColumns
+func collectMessages(rows pgx.Rows) ([]*Message, error) {
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
 }
\ No newline at end of file
@@ -2,49 +2,509 @@ package message
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/internal/core/unread"
+	"github.com/ivmello/go-api-template/pkg/diff"
+)
+
+var (
+	ErrForbidden        = errors.New("access forbidden")
+	ErrNotMember        = errors.New("not a member of the channel")
+	ErrInvalidSortOrder = errors.New("order must be asc or desc")
+	ErrInvalidPageSize  = errors.New("limit must not be negative")
+
+	ErrInvalidSearchQuery = errors.New("search query must be between 1 and 256 characters")
+	ErrInvalidRevision    = errors.New("revision numbers must be positive")
+)
+
+const (
+	// purgeBatchSize is the number of trashed messages deleted per statement
+	purgeBatchSize = 1000
+
+	// membershipRefresh is how long a watcher's channel memberships are
+	// cached before they are loaded again
+	membershipRefresh = 30 * time.Second
 )
 
+// Memberships checks which channels users belong to
+type Memberships interface {
+	IsMember(ctx context.Context, channelID, userID string) (bool, error)
+	ChannelIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// UnreadCounters keeps the unread counters of channel members in step with
+// the channel's messages
+type UnreadCounters interface {
+	Posted(ctx context.Context, target unread.Target, authorID string)
+	Recount(ctx context.Context, target unread.Target)
+}
+
+// Store persists messages. Repository implements it, and CachedRepository
+// adds read-through caching in front of it.
+type Store interface {
+	Create(ctx context.Context, message *Message) error
+	List(ctx context.Context, opts ListOptions, after *timeCursor, limit int) ([]*Message, error)
+	Search(ctx context.Context, language, query string, channelIDs []string, after *searchCursor, limit int) ([]*SearchResult, error)
+	GetByID(ctx context.Context, id string) (*Message, error)
+	Update(ctx context.Context, message *Message, editedBy string) error
+	ListRevisions(ctx context.Context, messageID string) ([]*Revision, error)
+	GetRevision(ctx context.Context, messageID string, number int) (*Revision, error)
+	GetDeletedByID(ctx context.Context, id string, cutoff time.Time) (*Message, error)
+	ListDeleted(ctx context.Context, userID string, cutoff time.Time, after *timeCursor, limit int) ([]*Message, error)
+	GetThread(ctx context.Context, id string, limit int) ([]*ThreadNode, error)
+	Delete(ctx context.Context, message *Message) error
+	Restore(ctx context.Context, message *Message) error
+	PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	EraseDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
 // Service provides message operations
 type Service struct {
-	repo *Repository
+	repo     Store
+	members  Memberships
+	counters UnreadCounters
+	events   *EventStream
+	cfg      config.MessagesConfig
+	search   config.SearchConfig
 }
 
 // NewService creates a new message service
-func NewService(repo *Repository) *Service {
+func NewService(repo Store, members Memberships, counters UnreadCounters, events *EventStream, messagesConfig config.MessagesConfig, searchConfig config.SearchConfig) *Service {
 	return &Service{
-		repo: repo,
+		repo:     repo,
+		members:  members,
+		counters: counters,
+		events:   events,
+		cfg:      messagesConfig,
+		search:   searchConfig,
 	}
 }
 
-// Create creates a new message
-func (s *Service) Create(ctx context.Context, userID, content string) (*Message, error) {
+// Create creates a new message in a channel the user is a member of, as a
+// reply to parentID when it is not empty
+func (s *Service) Create(ctx context.Context, channelID, userID, content, parentID string) (*Message, error) {
+	// Check access
+	if err := s.checkMember(ctx, channelID, userID); err != nil {
+		return nil, err
+	}
+
 	// Create message
-	message := NewMessage(userID, content)
+	message := NewMessage(channelID, userID, content, parentID)
 
 	// Save message to database
 	if err := s.repo.Create(ctx, message); err != nil {
 		return nil, err
 	}
 
+	s.counters.Posted(ctx, unread.ChannelTarget(channelID), userID)
+	s.events.Publish(ctx, EventCreated, message)
+
+	return message, nil
+}
+
+// List retrieves a page of messages from a channel the user is a member of.
+// The limit defaults to DefaultPageSize and is capped at MaxPageSize.
+func (s *Service) List(ctx context.Context, userID string, opts ListOptions) (*Page, error) {
+	// Validate options
+	switch opts.Order {
+	case "":
+		opts.Order = SortDesc
+	case SortAsc, SortDesc:
+	default:
+		return nil, ErrInvalidSortOrder
+	}
+	if opts.Limit < 0 {
+		return nil, ErrInvalidPageSize
+	}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultPageSize
+	}
+	if opts.Limit > MaxPageSize {
+		opts.Limit = MaxPageSize
+	}
+
+	var after *timeCursor
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Check access
+	if err := s.checkMember(ctx, opts.ChannelID, userID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra message to know whether another page follows
+	messages, err := s.repo.List(ctx, opts, after, opts.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Messages: messages}
+	if len(messages) > opts.Limit {
+		page.Messages = messages[:opts.Limit]
+		last := page.Messages[opts.Limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// Search runs a ranked full-text search over the content of messages in the
+// user's channels. The query uses web search syntax: quoted phrases, OR and
+// -negation.
+func (s *Service) Search(ctx context.Context, userID, query string, limit int, cursor string) (*SearchPage, error) {
+	// Validate options
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > MaxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+	if limit < 0 {
+		return nil, ErrInvalidPageSize
+	}
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	var after *searchCursor
+	if cursor != "" {
+		c, err := decodeSearchCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Only search the user's channels
+	channelIDs, err := s.members.ChannelIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(channelIDs) == 0 {
+		return &SearchPage{}, nil
+	}
+
+	// Fetch one extra result to know whether another page follows
+	results, err := s.repo.Search(ctx, s.search.Language, query, channelIDs, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SearchPage{Results: results}
+	if len(results) > limit {
+		page.Results = results[:limit]
+		page.NextCursor = encodeSearchCursor(page.Results[limit-1])
+	}
+
+	return page, nil
+}
+
+// Get retrieves a message from a channel the user is a member of
+func (s *Service) Get(ctx context.Context, id, userID string) (*Message, error) {
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check access
+	if err := s.checkMember(ctx, message.ChannelID, userID); err != nil {
+		return nil, err
+	}
+
 	return message, nil
 }
 
-// GetAll retrieves all messages
-func (s *Service) GetAll(ctx context.Context) ([]*Message, error) {
-	return s.repo.GetAll(ctx)
+// GetThread retrieves a message from a channel the user is a member of and
+// its replies at any depth. Deleted messages are kept as tombstones without
+// content while they have visible replies, and left out otherwise.
+func (s *Service) GetThread(ctx context.Context, id, userID string) (*Thread, error) {
+	// Fetch one extra message to know whether the thread was truncated
+	nodes, err := s.repo.GetThread(ctx, id, MaxThreadSize+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, ErrMessageNotFound
+	}
+
+	// Check access; replies are always in the channel of their parent
+	if err := s.checkMember(ctx, nodes[0].Message.ChannelID, userID); err != nil {
+		return nil, err
+	}
+
+	thread := &Thread{}
+	if len(nodes) > MaxThreadSize {
+		nodes = nodes[:MaxThreadSize]
+		thread.Truncated = true
+	}
+
+	// Nodes come ordered by depth, so parents are linked before their replies
+	byID := make(map[string]*ThreadNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.Message.ID] = node
+		if node.Depth == 0 {
+			thread.Root = node
+			continue
+		}
+		parent := byID[*node.Message.ParentID]
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	if thread.Root == nil || !prune(thread.Root) {
+		return nil, ErrMessageNotFound
+	}
+
+	return thread, nil
+}
+
+// prune drops deleted messages without visible replies from a thread, blanks
+// the content of the remaining tombstones, and reports whether anything
+// visible is left
+func prune(node *ThreadNode) bool {
+	replies := node.Replies[:0]
+	for _, reply := range node.Replies {
+		if prune(reply) {
+			replies = append(replies, reply)
+		}
+	}
+	node.Replies = replies
+
+	if node.Message.IsDeleted() {
+		if len(node.Replies) == 0 {
+			return false
+		}
+		node.Message.Content = ""
+	}
+	return true
+}
+
+// Update updates a message if the user is allowed to modify it
+func (s *Service) Update(ctx context.Context, id, userID string, roles []string, content string) error {
+	// Check access
+	message, err := s.authorize(ctx, id, userID, roles)
+	if err != nil {
+		return err
+	}
+
+	message.Content = content
+	if err := s.repo.Update(ctx, message, userID); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, EventUpdated, message)
+
+	return nil
+}
+
+// ListRevisions retrieves the edit history of a message, oldest first
+func (s *Service) ListRevisions(ctx context.Context, id, userID string) ([]*Revision, error) {
+	// Deleted messages have no visible history
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListRevisions(ctx, id)
+}
+
+// DiffRevisions returns the line-level difference between two revisions of
+// a message
+func (s *Service) DiffRevisions(ctx context.Context, id, userID string, from, to int) (*RevisionDiff, error) {
+	// Validate options
+	if from < 1 || to < 1 {
+		return nil, ErrInvalidRevision
+	}
+
+	// Deleted messages have no visible history
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.repo.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.repo.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RevisionDiff{
+		From:  fromRevision,
+		To:    toRevision,
+		Lines: diff.Lines(fromRevision.Content, toRevision.Content),
+	}, nil
+}
+
+// Delete moves a message to the trash if the user is allowed to modify it
+func (s *Service) Delete(ctx context.Context, id, userID string, roles []string) error {
+	// Check access
+	message, err := s.authorize(ctx, id, userID, roles)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, message); err != nil {
+		return err
+	}
+
+	// Deleted content is not sent to watchers
+	deletedAt := time.Now()
+	message.Content = ""
+	message.DeletedAt = &deletedAt
+	s.counters.Recount(ctx, unread.ChannelTarget(message.ChannelID))
+	s.events.Publish(ctx, EventDeleted, message)
+
+	return nil
+}
+
+// ListTrash retrieves a page of the user's deleted messages, most recently
+// deleted first
+func (s *Service) ListTrash(ctx context.Context, userID string, limit int, cursor string) (*Page, error) {
+	// Validate options
+	if limit < 0 {
+		return nil, ErrInvalidPageSize
+	}
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	var after *timeCursor
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	// Fetch one extra message to know whether another page follows
+	cutoff := time.Now().Add(-s.cfg.TrashRetention)
+	messages, err := s.repo.ListDeleted(ctx, userID, cutoff, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		last := page.Messages[limit-1]
+		page.NextCursor = encodeCursor(*last.DeletedAt, last.ID)
+	}
+
+	return page, nil
 }
 
-// GetByID retrieves a message by ID
-func (s *Service) GetByID(ctx context.Context, id string) (*Message, error) {
-	return s.repo.GetByID(ctx, id)
+// Restore takes a message out of the trash if the user is allowed to modify it
+func (s *Service) Restore(ctx context.Context, id, userID string, roles []string) error {
+	// Messages past the retention period can no longer be restored
+	cutoff := time.Now().Add(-s.cfg.TrashRetention)
+	message, err := s.repo.GetDeletedByID(ctx, id, cutoff)
+	if err != nil {
+		return err
+	}
+
+	// Check access
+	if !CanModify(message, userID, roles) {
+		return ErrForbidden
+	}
+
+	if err := s.repo.Restore(ctx, message); err != nil {
+		return err
+	}
+
+	message.DeletedAt = nil
+	s.counters.Recount(ctx, unread.ChannelTarget(message.ChannelID))
+	s.events.Publish(ctx, EventRestored, message)
+
+	return nil
 }
 
-// Update updates a message
-func (s *Service) Update(ctx context.Context, id, userID, content string) error {
-	return s.repo.Update(ctx, id, userID, content)
+// Watch subscribes to events from the channels the user is a member of.
+// With an empty lastEventID only new events are delivered; otherwise delivery
+// resumes right after that event. Joining or leaving a channel takes effect
+// within membershipRefresh.
+func (s *Service) Watch(ctx context.Context, userID, lastEventID string) (*Subscription, error) {
+	var channels map[string]bool
+	var loadedAt time.Time
+
+	return s.events.Subscribe(ctx, lastEventID, func(event *Event) (bool, error) {
+		if channels == nil || time.Since(loadedAt) > membershipRefresh {
+			ids, err := s.members.ChannelIDs(ctx, userID)
+			if err != nil {
+				return false, err
+			}
+			channels = make(map[string]bool, len(ids))
+			for _, id := range ids {
+				channels[id] = true
+			}
+			loadedAt = time.Now()
+		}
+		return channels[event.Message.ChannelID], nil
+	})
+}
+
+// PurgeTrash permanently deletes messages that have been in the trash for
+// longer than the retention period and returns how many were deleted.
+// Messages with replies are kept as tombstones and only have their content
+// erased.
+func (s *Service) PurgeTrash(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-s.cfg.TrashRetention)
+
+	// Deleting replies can free their parents, so repeat until nothing is left
+	var total int64
+	for {
+		purged, err := s.repo.PurgeDeleted(ctx, cutoff, purgeBatchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged == 0 {
+			break
+		}
+	}
+
+	if _, err := s.repo.EraseDeleted(ctx, cutoff); err != nil {
+		return total, err
+	}
+
+	return total, nil
+}
+
+// checkMember returns ErrNotMember unless the user is a member of the channel
+func (s *Service) checkMember(ctx context.Context, channelID, userID string) error {
+	member, err := s.members.IsMember(ctx, channelID, userID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return ErrNotMember
+	}
+	return nil
 }
 
-// Delete deletes a message
-func (s *Service) Delete(ctx context.Context, id, userID string) error {
-	return s.repo.Delete(ctx, id, userID)
+// authorize loads a message and applies the modification policy
+func (s *Service) authorize(ctx context.Context, id, userID string, roles []string) (*Message, error) {
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanModify(message, userID, roles) {
+		return nil, ErrForbidden
+	}
+
+	return message, nil
 }
\ No newline at end of file
@@ -0,0 +1,68 @@
+package reaction
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxEmojiLength bounds the size of a reaction in bytes, enough for emoji
+// sequences joined with modifiers
+const MaxEmojiLength = 32
+
+// Reaction is a user's emoji reaction to a message
+type Reaction struct {
+	MessageID string    `json:"message_id"`
+	UserID    string    `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewReaction creates a new reaction
+func NewReaction(messageID, userID, emoji string) *Reaction {
+	return &Reaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Count is the number of users who reacted to a message with an emoji
+type Count struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// Summary aggregates the reactions to a message. Counts are ordered by
+// popularity, then by first use. Mine lists the viewer's own reactions.
+type Summary struct {
+	Counts []Count  `json:"counts"`
+	Mine   []string `json:"mine"`
+}
+
+// IsEmoji reports whether s is a single emoji or emoji sequence: symbols,
+// optionally combined with variation selectors, skin tone modifiers, zero
+// width joiners and tags, or a keycap such as 1️⃣
+func IsEmoji(s string) bool {
+	if s == "" || len(s) > MaxEmojiLength || !utf8.ValidString(s) {
+		return false
+	}
+
+	hasSymbol := false
+	for i, r := range s {
+		switch {
+		case unicode.Is(unicode.So, r), r == '\u20e3': // Symbol or combining keycap
+			hasSymbol = true
+		case r == '\u200d', // Zero width joiner
+			r == '\ufe0f',                // Emoji presentation selector
+			r >= 0x1f3fb && r <= 0x1f3ff, // Skin tone modifiers
+			r >= 0xe0020 && r <= 0xe007f: // Tags used by subdivision flags
+		case i == 0 && strings.ContainsRune("0123456789#*", r): // Keycap base
+		default:
+			return false
+		}
+	}
+	return hasSymbol
+}
\ No newline at end of file
@@ -0,0 +1,108 @@
+package reaction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrReactionNotFound = errors.New("reaction not found")
+)
+
+// Repository provides access to reaction storage
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new reaction repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// Add inserts a reaction. Adding a reaction the user already made is a no-op.
+func (r *Repository) Add(ctx context.Context, reaction *Reaction) error {
+	query := `
+		INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		reaction.MessageID,
+		reaction.UserID,
+		reaction.Emoji,
+		reaction.CreatedAt,
+	)
+	return err
+}
+
+// Remove deletes a user's reaction to a message
+func (r *Repository) Remove(ctx context.Context, messageID, userID, emoji string) error {
+	result, err := r.db.Exec(ctx,
+		"DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3",
+		messageID, userID, emoji,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrReactionNotFound
+	}
+
+	return nil
+}
+
+// Summaries aggregates the reactions to the given messages in a single
+// query, keyed by message ID. Messages without reactions are left out.
+// viewerID may be empty for anonymous viewers.
+func (r *Repository) Summaries(ctx context.Context, messageIDs []string, viewerID string) (map[string]*Summary, error) {
+	query := `
+		SELECT message_id, emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2::uuid), false)
+		FROM message_reactions
+		WHERE message_id = ANY($1::uuid[])
+		GROUP BY message_id, emoji
+		ORDER BY message_id, COUNT(*) DESC, MIN(created_at), emoji
+	`
+
+	var viewer *string
+	if viewerID != "" {
+		viewer = &viewerID
+	}
+
+	rows, err := r.db.Query(ctx, query, messageIDs, viewer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make(map[string]*Summary)
+	for rows.Next() {
+		var messageID string
+		var count Count
+		var mine bool
+		if err := rows.Scan(&messageID, &count.Emoji, &count.Count, &mine); err != nil {
+			return nil, err
+		}
+
+		summary, ok := summaries[messageID]
+		if !ok {
+			summary = &Summary{}
+			summaries[messageID] = summary
+		}
+		summary.Counts = append(summary.Counts, count)
+		if mine {
+			summary.Mine = append(summary.Mine, count.Emoji)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
\ No newline at end of file
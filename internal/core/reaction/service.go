@@ -0,0 +1,63 @@
+package reaction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ivmello/go-api-template/internal/core/message"
+)
+
+var (
+	ErrInvalidEmoji = errors.New("reaction must be a single emoji")
+)
+
+// MessageGetter loads the message being reacted to from a channel the user
+// is a member of
+type MessageGetter interface {
+	Get(ctx context.Context, id, userID string) (*message.Message, error)
+}
+
+// Service provides reaction operations
+type Service struct {
+	repo     *Repository
+	messages MessageGetter
+}
+
+// NewService creates a new reaction service
+func NewService(repo *Repository, messages MessageGetter) *Service {
+	return &Service{
+		repo:     repo,
+		messages: messages,
+	}
+}
+
+// Add reacts to a message with an emoji on behalf of the user. Reacting
+// twice with the same emoji has no further effect.
+func (s *Service) Add(ctx context.Context, messageID, userID, emoji string) error {
+	// Validate reaction
+	if !IsEmoji(emoji) {
+		return ErrInvalidEmoji
+	}
+
+	// Deleted messages and messages outside the user's channels can't be
+	// reacted to
+	if _, err := s.messages.Get(ctx, messageID, userID); err != nil {
+		return err
+	}
+
+	return s.repo.Add(ctx, NewReaction(messageID, userID, emoji))
+}
+
+// Remove takes back the user's reaction to a message
+func (s *Service) Remove(ctx context.Context, messageID, userID, emoji string) error {
+	return s.repo.Remove(ctx, messageID, userID, emoji)
+}
+
+// Summaries aggregates the reactions to the given messages, keyed by message
+// ID, with the viewer's own reactions marked. viewerID may be empty.
+func (s *Service) Summaries(ctx context.Context, messageIDs []string, viewerID string) (map[string]*Summary, error) {
+	if len(messageIDs) == 0 {
+		return map[string]*Summary{}, nil
+	}
+	return s.repo.Summaries(ctx, messageIDs, viewerID)
+}
\ No newline at end of file
@@ -0,0 +1,66 @@
+package unread
+
+import "strings"
+
+// Kinds of targets that unread messages are counted in
+const (
+	KindChannel      = "channel"
+	KindConversation = "conversation"
+)
+
+// Target is a channel or conversation whose unread messages are counted
+type Target struct {
+	Kind string
+	ID   string
+}
+
+// ChannelTarget returns the target for a channel
+func ChannelTarget(id string) Target {
+	return Target{Kind: KindChannel, ID: id}
+}
+
+// ConversationTarget returns the target for a conversation
+func ConversationTarget(id string) Target {
+	return Target{Kind: KindConversation, ID: id}
+}
+
+// field returns the field holding the target's count in a user's counters
+func (t Target) field() string {
+	return t.Kind + ":" + t.ID
+}
+
+// parseField returns the target of a counter field
+func parseField(field string) (Target, bool) {
+	kind, id, ok := strings.Cut(field, ":")
+	if !ok || (kind != KindChannel && kind != KindConversation) {
+		return Target{}, false
+	}
+	return Target{Kind: kind, ID: id}, true
+}
+
+// Count is the number of unread messages of a user in one target. Messages
+// are unread when another user sent them after the reader joined and after
+// the reader's last-read pointer.
+type Count struct {
+	Target Target
+	Unread int
+}
+
+// Summary is a user's unread messages across their channels and
+// conversations. Counts only lists targets with unread messages.
+type Summary struct {
+	Total  int
+	Counts []*Count
+}
+
+// newSummary builds a summary from per-target counts
+func newSummary(counts []*Count) *Summary {
+	summary := &Summary{}
+	for _, count := range counts {
+		if count.Unread > 0 {
+			summary.Total += count.Unread
+			summary.Counts = append(summary.Counts, count)
+		}
+	}
+	return summary
+}
\ No newline at end of file
@@ -0,0 +1,241 @@
+package unread
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrUnknownKind     = errors.New("unknown unread target kind")
+	ErrMessageNotFound = errors.New("message not found")
+)
+
+// readerTable describes where the readers of one kind of target, their
+// last-read pointers and the target's messages are stored
+type readerTable struct {
+	table    string // Readers of the targets
+	target   string // Column holding the target ID, in both tables
+	messages string // Messages of the targets
+	visible  string // Condition on the messages m that can be unread
+}
+
+// readerTables lists the reader table of every target kind
+var readerTables = map[string]readerTable{
+	KindChannel: {
+		table:    "channel_members",
+		target:   "channel_id",
+		messages: "messages",
+		visible:  "m.deleted_at IS NULL",
+	},
+	KindConversation: {
+		table:    "conversation_participants",
+		target:   "conversation_id",
+		messages: "conversation_messages",
+		visible:  "TRUE",
+	},
+}
+
+// count returns a subquery counting the unread messages of the reader row
+// aliased as alias
+func (t readerTable) count(alias string) string {
+	return fmt.Sprintf(`(SELECT COUNT(*) FROM %[2]s m
+		WHERE m.%[3]s = %[1]s.%[3]s AND %[4]s
+			AND m.user_id <> %[1]s.user_id AND m.created_at > %[1]s.joined_at
+			AND (%[1]s.last_read_message_at IS NULL OR (m.created_at, m.id) > (%[1]s.last_read_message_at, %[1]s.last_read_message_id)))`,
+		alias, t.messages, t.target, t.visible,
+	)
+}
+
+// CountQuery returns a subquery counting the unread messages of a reader of
+// a target kind, for queries on the kind's reader table aliased as alias
+func CountQuery(kind, alias string) string {
+	return readerTables[kind].count(alias)
+}
+
+// MarkReadQuery returns the statement moving the last-read pointer of user $2
+// in target $1 of a kind forward to message $3 created at $4. The pointer
+// never moves back to an older message.
+func MarkReadQuery(kind string) string {
+	t := readerTables[kind]
+	return fmt.Sprintf(`
+		UPDATE %s
+		SET last_read_message_id = $3, last_read_message_at = $4
+		WHERE %s = $1 AND user_id = $2
+			AND (last_read_message_at IS NULL OR (last_read_message_at, last_read_message_id) < ($4, $3::uuid))
+	`, t.table, t.target)
+}
+
+// MarkRead moves a user's last-read pointer in a target forward to one of its
+// messages: the given message, or else the newest message sent at or before
+// readAt. It returns ErrMessageNotFound when the target has no such message.
+func MarkRead(ctx context.Context, db *pgxpool.Pool, target Target, userID, messageID string, readAt *time.Time) error {
+	t, ok := readerTables[target.Kind]
+	if !ok {
+		return ErrUnknownKind
+	}
+
+	var createdAt time.Time
+	if messageID != "" {
+		query := fmt.Sprintf("SELECT created_at FROM %s WHERE id = $1 AND %s = $2", t.messages, t.target)
+		err := db.QueryRow(ctx, query, messageID, target.ID).Scan(&createdAt)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrMessageNotFound
+			}
+			return err
+		}
+	} else {
+		query := fmt.Sprintf(`
+			SELECT id, created_at FROM %s
+			WHERE %s = $1 AND created_at <= $2
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		`, t.messages, t.target)
+		err := db.QueryRow(ctx, query, target.ID, readAt).Scan(&messageID, &createdAt)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil // Nothing was sent yet, so nothing is unread
+			}
+			return err
+		}
+	}
+
+	_, err := db.Exec(ctx, MarkReadQuery(target.Kind), target.ID, userID, messageID, createdAt)
+	return err
+}
+
+// Repository counts unread messages from the read pointers in Postgres,
+// which are the durable copy of the counters
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new unread repository
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+// CountUser counts a user's unread messages in every channel and
+// conversation they read
+func (r *Repository) CountUser(ctx context.Context, userID string) ([]*Count, error) {
+	channels, conversations := readerTables[KindChannel], readerTables[KindConversation]
+	query := fmt.Sprintf(`
+		SELECT $2::text, r.%s, %s FROM %s r WHERE r.user_id = $1
+		UNION ALL
+		SELECT $3::text, r.%s, %s FROM %s r WHERE r.user_id = $1
+	`,
+		channels.target, channels.count("r"), channels.table,
+		conversations.target, conversations.count("r"), conversations.table,
+	)
+
+	rows, err := r.db.Query(ctx, query, userID, KindChannel, KindConversation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []*Count
+	for rows.Next() {
+		count := &Count{}
+		if err := rows.Scan(&count.Target.Kind, &count.Target.ID, &count.Unread); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// Count counts a user's unread messages in a target. Users who don't read
+// the target have none.
+func (r *Repository) Count(ctx context.Context, userID string, target Target) (int, error) {
+	t, ok := readerTables[target.Kind]
+	if !ok {
+		return 0, ErrUnknownKind
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s r WHERE r.%s = $1 AND r.user_id = $2", t.count("r"), t.table, t.target)
+
+	var count int
+	err := r.db.QueryRow(ctx, query, target.ID, userID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountTarget counts the unread messages of every reader of a target,
+// keyed by user ID
+func (r *Repository) CountTarget(ctx context.Context, target Target) (map[string]int, error) {
+	t, ok := readerTables[target.Kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	query := fmt.Sprintf("SELECT r.user_id, %s FROM %s r WHERE r.%s = $1", t.count("r"), t.table, t.target)
+
+	rows, err := r.db.Query(ctx, query, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// ListReaders retrieves the IDs of the users who read a target
+func (r *Repository) ListReaders(ctx context.Context, target Target) ([]string, error) {
+	t, ok := readerTables[target.Kind]
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE %s = $1", t.table, t.target)
+
+	rows, err := r.db.Query(ctx, query, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
\ No newline at end of file
@@ -0,0 +1,201 @@
+package unread
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// incrementBatchSize bounds the counters incremented per round trip
+const incrementBatchSize = 500
+
+// Service keeps users' unread message counters. Counters live in Redis for
+// fast reads and are derived from the read pointers in Postgres, which stay
+// the source of truth: counters that are missing are rebuilt from Postgres,
+// and Reconcile repairs any that drifted.
+//
+// Counters are updated after the change they follow is committed, so
+// failures are logged rather than returned.
+type Service struct {
+	repo   *Repository
+	store  *Store
+	logger *slog.Logger
+}
+
+// NewService creates a new unread service
+func NewService(repo *Repository, store *Store, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Posted counts a new message in a target as unread for every reader but
+// its author. The message is already committed, so the counters are
+// incremented even when the request is canceled.
+func (s *Service) Posted(ctx context.Context, target Target, authorID string) {
+	ctx = context.WithoutCancel(ctx)
+
+	readers, err := s.repo.ListReaders(ctx, target)
+	if err != nil {
+		s.logger.Error("Failed to list unread counter readers", "error", err, "kind", target.Kind, "id", target.ID)
+		return
+	}
+
+	userIDs := make([]string, 0, len(readers))
+	for _, userID := range readers {
+		if userID != authorID {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	for len(userIDs) > 0 {
+		batch := userIDs[:min(len(userIDs), incrementBatchSize)]
+		userIDs = userIDs[len(batch):]
+
+		if err := s.store.Increment(ctx, target, batch); err != nil {
+			s.logger.Error("Failed to increment unread counters", "error", err, "kind", target.Kind, "id", target.ID)
+			return
+		}
+	}
+}
+
+// Refresh recounts a user's unread messages in a target after their
+// last-read pointer moved
+func (s *Service) Refresh(ctx context.Context, userID string, target Target) {
+	count, err := s.repo.Count(ctx, userID, target)
+	if err != nil {
+		s.logger.Error("Failed to count unread messages", "error", err, "user_id", userID, "kind", target.Kind, "id", target.ID)
+		return
+	}
+
+	if err := s.store.Set(ctx, target, map[string]int{userID: count}); err != nil {
+		s.logger.Error("Failed to set unread counter", "error", err, "user_id", userID, "kind", target.Kind, "id", target.ID)
+	}
+}
+
+// Recount recounts the unread messages of every reader of a target after
+// messages were removed from it or brought back
+func (s *Service) Recount(ctx context.Context, target Target) {
+	counts, err := s.repo.CountTarget(ctx, target)
+	if err != nil {
+		s.logger.Error("Failed to count unread messages", "error", err, "kind", target.Kind, "id", target.ID)
+		return
+	}
+
+	if err := s.store.Set(ctx, target, counts); err != nil {
+		s.logger.Error("Failed to set unread counters", "error", err, "kind", target.Kind, "id", target.ID)
+	}
+}
+
+// Forget drops a user's counter for a target they no longer read
+func (s *Service) Forget(ctx context.Context, userID string, target Target) {
+	if err := s.store.Delete(ctx, userID, target); err != nil {
+		s.logger.Error("Failed to delete unread counter", "error", err, "user_id", userID, "kind", target.Kind, "id", target.ID)
+	}
+}
+
+// Summary returns a user's unread messages across their channels and
+// conversations. It reads the counters from Redis, building them from
+// Postgres when they are missing, and falls back to Postgres when Redis is
+// unavailable.
+func (s *Service) Summary(ctx context.Context, userID string) (*Summary, error) {
+	counts, loaded, err := s.store.Get(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to read unread counters", "error", err, "user_id", userID)
+	}
+	if err == nil && loaded {
+		return newSummary(counts), nil
+	}
+
+	// Build the counters from Postgres
+	counts, err = s.repo.CountUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Replace(ctx, userID, counts, false); err != nil {
+		s.logger.Error("Failed to store unread counters", "error", err, "user_id", userID)
+	}
+
+	return newSummary(counts), nil
+}
+
+// ClaimReconcile takes the reconciliation lease for the given duration. It
+// returns false when another instance holds it, so counters are reconciled
+// by one instance at a time.
+func (s *Service) ClaimReconcile(ctx context.Context, lease time.Duration) (bool, error) {
+	return s.store.Lease(ctx, reconcileLeaseKey, lease)
+}
+
+// Reconcile compares the counters of every user that has them in Redis with
+// the counts in Postgres, and repairs the ones that drifted. Incomplete
+// counters are dropped so they are rebuilt when next read. Counts that
+// change while a user is being checked may be repaired on the next run.
+func (s *Service) Reconcile(ctx context.Context) (checked, repaired int, err error) {
+	var cursor uint64
+	for {
+		userIDs, next, err := s.store.Scan(ctx, cursor)
+		if err != nil {
+			return checked, repaired, err
+		}
+
+		for _, userID := range userIDs {
+			fixed, err := s.reconcileUser(ctx, userID)
+			if err != nil {
+				return checked, repaired, err
+			}
+			checked++
+			if fixed {
+				repaired++
+			}
+		}
+
+		if next == 0 {
+			return checked, repaired, nil
+		}
+		cursor = next
+	}
+}
+
+// reconcileUser repairs a user's counters if they differ from Postgres
+func (s *Service) reconcileUser(ctx context.Context, userID string) (bool, error) {
+	stored, loaded, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !loaded {
+		return true, s.store.Drop(ctx, userID)
+	}
+
+	counts, err := s.repo.CountUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	if sameCounts(newSummary(stored), newSummary(counts)) {
+		return false, nil
+	}
+
+	s.logger.Warn("Repairing drifted unread counters", "user_id", userID)
+	return true, s.store.Replace(ctx, userID, counts, true)
+}
+
+// sameCounts reports whether two summaries hold the same counts
+func sameCounts(a, b *Summary) bool {
+	if a.Total != b.Total || len(a.Counts) != len(b.Counts) {
+		return false
+	}
+
+	counts := make(map[Target]int, len(a.Counts))
+	for _, count := range a.Counts {
+		counts[count.Target] = count.Unread
+	}
+	for _, count := range b.Counts {
+		if counts[count.Target] != count.Unread {
+			return false
+		}
+	}
+	return true
+}
\ No newline at end of file
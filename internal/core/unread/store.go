@@ -0,0 +1,190 @@
+package unread
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	counterKeyPrefix = "unread:"
+
+	// loadedField marks counters that were fully built from Postgres. Counters
+	// without it are incomplete and are rebuilt before they are read.
+	loadedField = "loaded"
+
+	// scanBatchSize is the number of keys requested per SCAN round trip
+	scanBatchSize = 100
+
+	// reconcileLeaseKey is held by the instance reconciling counters. It is
+	// outside counterKeyPrefix so Scan doesn't take it for a user.
+	reconcileLeaseKey = "unread-reconcile:lease"
+)
+
+// incrementScript adds one to a field of every existing hash. Missing
+// counters are left alone: they are built from Postgres when next read.
+var incrementScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if redis.call("EXISTS", key) == 1 then
+		redis.call("HINCRBY", key, ARGV[1], 1)
+	end
+end
+return 0
+`)
+
+// setScript sets a field of every existing hash to the matching count,
+// deleting the field for a count of zero
+var setScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+	if redis.call("EXISTS", key) == 1 then
+		if tonumber(ARGV[i + 1]) > 0 then
+			redis.call("HSET", key, ARGV[1], ARGV[i + 1])
+		else
+			redis.call("HDEL", key, ARGV[1])
+		end
+	end
+end
+return 0
+`)
+
+// Store keeps each user's unread counts in a Redis hash with a field per
+// channel or conversation that has unread messages
+type Store struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewStore creates a new counter store. Counters expire ttl after they were
+// built, so the counters of inactive users don't pile up.
+func NewStore(client *redis.Client, ttl time.Duration) *Store {
+	return &Store{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// Get returns a user's counts. loaded is false when the user's counters are
+// missing or incomplete.
+func (s *Store) Get(ctx context.Context, userID string) (counts []*Count, loaded bool, err error) {
+	values, err := s.client.HGetAll(ctx, counterKeyPrefix+userID).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	for field, value := range values {
+		if field == loadedField {
+			loaded = true
+			continue
+		}
+		target, ok := parseField(field)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, false, err
+		}
+		counts = append(counts, &Count{Target: target, Unread: n})
+	}
+
+	return counts, loaded, nil
+}
+
+// Replace overwrites a user's counters with complete counts. The counters
+// expire after the store's TTL, or keep their remaining time to live when
+// keepTTL is set.
+func (s *Store) Replace(ctx context.Context, userID string, counts []*Count, keepTTL bool) error {
+	key := counterKeyPrefix + userID
+
+	ttl := s.ttl
+	if keepTTL {
+		remaining, err := s.client.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			ttl = remaining
+		}
+	}
+
+	values := []interface{}{loadedField, 1}
+	for _, count := range counts {
+		if count.Unread > 0 {
+			values = append(values, count.Target.field(), count.Unread)
+		}
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, values...)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// Increment adds one unread message in the target for each user
+func (s *Store) Increment(ctx context.Context, target Target, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = counterKeyPrefix + userID
+	}
+
+	return incrementScript.Run(ctx, s.client, keys, target.field()).Err()
+}
+
+// Set overwrites the counts of users in the target
+func (s *Store) Set(ctx context.Context, target Target, counts map[string]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(counts))
+	args := make([]interface{}, 0, len(counts)+1)
+	args = append(args, target.field())
+	for userID, n := range counts {
+		keys = append(keys, counterKeyPrefix+userID)
+		args = append(args, n)
+	}
+
+	return setScript.Run(ctx, s.client, keys, args...).Err()
+}
+
+// Delete removes a user's count for the target
+func (s *Store) Delete(ctx context.Context, userID string, target Target) error {
+	return s.client.HDel(ctx, counterKeyPrefix+userID, target.field()).Err()
+}
+
+// Drop removes all of a user's counters
+func (s *Store) Drop(ctx context.Context, userID string) error {
+	return s.client.Del(ctx, counterKeyPrefix+userID).Err()
+}
+
+// Scan returns a batch of users who have counters, starting at the cursor.
+// The returned cursor is zero once every user was returned.
+func (s *Store) Scan(ctx context.Context, cursor uint64) ([]string, uint64, error) {
+	keys, next, err := s.client.Scan(ctx, cursor, counterKeyPrefix+"*", scanBatchSize).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs := make([]string, len(keys))
+	for i, key := range keys {
+		userIDs[i] = strings.TrimPrefix(key, counterKeyPrefix)
+	}
+
+	return userIDs, next, nil
+}
+
+// Lease takes a lease on key for the given duration, reporting whether it was
+// free. The lease isn't released; it expires.
+func (s *Store) Lease(ctx context.Context, key string, duration time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, 1, duration).Result()
+}
\ No newline at end of file
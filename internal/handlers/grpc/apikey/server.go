@@ -0,0 +1,134 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/core/apikey"
+	"github.com/ivmello/go-api-template/internal/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the ApiKeyService gRPC server
+type Server struct {
+	UnimplementedApiKeyServiceServer
+	service *apikey.Service
+}
+
+// NewServer creates a new API key gRPC server
+func NewServer(service *apikey.Service) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// CreateApiKey creates a new API key for the current user
+func (s *Server) CreateApiKey(ctx context.Context, req *CreateApiKeyRequest) (*CreateApiKeyResponse, error) {
+	// Validate request
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name and scopes are required")
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "expires_at must be an RFC 3339 timestamp")
+		}
+		expiresAt = &t
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Create API key
+	key, plaintext, err := s.service.Create(ctx, userID, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, apikey.ErrInvalidScope) || errors.Is(err, apikey.ErrInvalidExpiry) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &CreateApiKeyResponse{
+		ApiKey: newApiKeyResponse(key),
+		Key:    plaintext,
+	}, nil
+}
+
+// ListApiKeys lists the current user's API keys
+func (s *Server) ListApiKeys(ctx context.Context, req *ListApiKeysRequest) (*ListApiKeysResponse, error) {
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	keys, err := s.service.List(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Map domain objects to response objects
+	response := &ListApiKeysResponse{
+		ApiKeys: make([]*ApiKeyResponse, len(keys)),
+	}
+	for i, key := range keys {
+		response.ApiKeys[i] = newApiKeyResponse(key)
+	}
+
+	return response, nil
+}
+
+// RevokeApiKey revokes one of the current user's API keys
+func (s *Server) RevokeApiKey(ctx context.Context, req *RevokeApiKeyRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Revoke API key
+	if err := s.service.Revoke(ctx, req.Id, userID); err != nil {
+		code := codes.Internal
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// newApiKeyResponse maps an API key to its gRPC representation
+func newApiKeyResponse(key *apikey.APIKey) *ApiKeyResponse {
+	return &ApiKeyResponse{
+		Id:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  formatTime(key.ExpiresAt),
+		LastUsedAt: formatTime(key.LastUsedAt),
+		RevokedAt:  formatTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// formatTime formats an optional time as RFC 3339, or an empty string if unset
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
\ No newline at end of file
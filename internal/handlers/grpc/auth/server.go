@@ -3,11 +3,15 @@ package auth
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/ivmello/go-api-template/internal/core/auth"
 	"github.com/ivmello/go-api-template/internal/middleware"
+	"github.com/ivmello/go-api-template/pkg/validator"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -30,6 +34,15 @@ func (s *Server) Register(ctx context.Context, req *RegisterRequest) (*UserRespo
 	if req.Email == "" || req.Password == "" || req.Name == "" {
 		return nil, status.Error(codes.InvalidArgument, "email, password, and name are required")
 	}
+	if err := validator.ValidateEmail(req.Email); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(req.Password) < 6 {
+		return nil, status.Error(codes.InvalidArgument, "password must be at least 6 characters")
+	}
+	if len(req.Name) < 2 {
+		return nil, status.Error(codes.InvalidArgument, "name must be at least 2 characters")
+	}
 
 	// Register user
 	user, err := s.service.Register(ctx, req.Email, req.Password, req.Name)
@@ -42,12 +55,7 @@ func (s *Server) Register(ctx context.Context, req *RegisterRequest) (*UserRespo
 	}
 
 	// Return user
-	return &UserResponse{
-		Id:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-	}, nil
+	return newUserResponse(user), nil
 }
 
 // Login authenticates a user
@@ -58,18 +66,172 @@ func (s *Server) Login(ctx context.Context, req *LoginRequest) (*TokenResponse,
 	}
 
 	// Login user
-	token, err := s.service.Login(ctx, req.Email, req.Password)
+	result, err := s.service.Login(ctx, req.Email, req.Password, middleware.GetClientIPFromContext(ctx))
 	if err != nil {
+		var locked *auth.LockedError
+		if errors.As(err, &locked) {
+			return nil, lockedStatus(ctx, locked)
+		}
+
 		code := codes.Internal
 		if errors.Is(err, auth.ErrInvalidCredentials) {
 			code = codes.Unauthenticated
+		} else if errors.Is(err, auth.ErrEmailNotVerified) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Ask for the second factor
+	if result.Challenge != nil {
+		return &TokenResponse{
+			MfaRequired: true,
+			MfaToken:    result.Challenge.Token,
+			ExpiresAt:   result.Challenge.ExpiresAt.Format(time.RFC3339),
+		}, nil
+	}
+
+	// Return tokens
+	return &TokenResponse{
+		Token:        result.Tokens.AccessToken,
+		RefreshToken: result.Tokens.RefreshToken,
+		ExpiresAt:    result.Tokens.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// VerifyMFA completes a two-factor login
+func (s *Server) VerifyMFA(ctx context.Context, req *VerifyMFARequest) (*TokenResponse, error) {
+	// Validate request
+	if req.MfaToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "mfa_token and code are required")
+	}
+
+	// Verify second factor
+	tokens, err := s.service.VerifyMFA(ctx, req.MfaToken, req.Code, middleware.GetClientIPFromContext(ctx))
+	if err != nil {
+		var locked *auth.LockedError
+		if errors.As(err, &locked) {
+			return nil, lockedStatus(ctx, locked)
+		}
+
+		code := codes.Internal
+		if errors.Is(err, auth.ErrInvalidMFAToken) || errors.Is(err, auth.ErrInvalidMFACode) {
+			code = codes.Unauthenticated
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Return tokens
+	return &TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// EnrollTOTP starts two-factor enrollment for the current user
+func (s *Server) EnrollTOTP(ctx context.Context, req *EnrollTOTPRequest) (*EnrollTOTPResponse, error) {
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Start enrollment
+	enrollment, err := s.service.EnrollTOTP(ctx, userID)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrTOTPAlreadyEnabled) {
+			code = codes.AlreadyExists
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EnrollTOTPResponse{
+		Secret:     enrollment.Secret,
+		OtpauthUri: enrollment.URI,
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication for the current user
+func (s *Server) ConfirmTOTP(ctx context.Context, req *ConfirmTOTPRequest) (*RecoveryCodesResponse, error) {
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Validate request
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	// Enable two-factor authentication
+	recoveryCodes, err := s.service.ConfirmTOTP(ctx, userID, req.Code)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrInvalidMFACode) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, auth.ErrTOTPNotEnrolled) {
+			code = codes.FailedPrecondition
+		} else if errors.Is(err, auth.ErrTOTPAlreadyEnabled) {
+			code = codes.AlreadyExists
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableTOTP turns off two-factor authentication for the current user
+func (s *Server) DisableTOTP(ctx context.Context, req *DisableTOTPRequest) (*EmptyResponse, error) {
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Validate request
+	if req.Password == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "password and code are required")
+	}
+
+	// Disable two-factor authentication
+	if err := s.service.DisableTOTP(ctx, userID, req.Password, req.Code); err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrTOTPNotEnabled) {
+			code = codes.FailedPrecondition
+		} else if errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, auth.ErrInvalidMFACode) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair
+func (s *Server) Refresh(ctx context.Context, req *RefreshRequest) (*TokenResponse, error) {
+	// Validate request
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	// Rotate refresh token
+	tokens, err := s.service.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			code = codes.Unauthenticated
 		}
 		return nil, status.Error(code, err.Error())
 	}
 
-	// Return token
+	// Return tokens
 	return &TokenResponse{
-		Token: token,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt.Format(time.RFC3339),
 	}, nil
 }
 
@@ -92,10 +254,148 @@ func (s *Server) GetCurrentUser(ctx context.Context, req *GetCurrentUserRequest)
 	}
 
 	// Return user
+	return newUserResponse(user), nil
+}
+
+// Logout revokes the current access token and, optionally, its refresh token family
+func (s *Server) Logout(ctx context.Context, req *LogoutRequest) (*EmptyResponse, error) {
+	// Get claims from context (set by auth middleware)
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Revoke tokens
+	if err := s.service.Logout(ctx, claims, req.RefreshToken); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// LogoutAll revokes every token issued to the current user
+func (s *Server) LogoutAll(ctx context.Context, req *LogoutAllRequest) (*EmptyResponse, error) {
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Revoke all tokens
+	if err := s.service.LogoutAll(ctx, userID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// SetUserRoles replaces the roles of a user
+func (s *Server) SetUserRoles(ctx context.Context, req *SetUserRolesRequest) (*UserResponse, error) {
+	// Validate request
+	if req.UserId == "" || len(req.Roles) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id and roles are required")
+	}
+
+	// Update roles
+	user, err := s.service.SetUserRoles(ctx, req.UserId, req.Roles)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrInvalidRole) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, auth.ErrUserNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Return user
+	return newUserResponse(user), nil
+}
+
+// ForgotPassword emails a password reset link if the account exists
+func (s *Server) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	// Request reset
+	if err := s.service.RequestPasswordReset(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// ResetPassword sets a new password using a reset token
+func (s *Server) ResetPassword(ctx context.Context, req *ResetPasswordRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Token == "" || len(req.Password) < 6 {
+		return nil, status.Error(codes.InvalidArgument, "token and a password of at least 6 characters are required")
+	}
+
+	// Reset password
+	if err := s.service.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrInvalidResetToken) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// VerifyEmail confirms an email address with a verification token
+func (s *Server) VerifyEmail(ctx context.Context, req *VerifyEmailRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	// Verify email
+	if err := s.service.VerifyEmail(ctx, req.Token); err != nil {
+		code := codes.Internal
+		if errors.Is(err, auth.ErrInvalidVerifyToken) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// ResendVerificationEmail sends a new verification link if the account is unverified
+func (s *Server) ResendVerificationEmail(ctx context.Context, req *ResendVerificationEmailRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	// Resend verification
+	if err := s.service.ResendVerificationEmail(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// lockedStatus reports a login lockout as ResourceExhausted and sends the
+// wait in the retry-after response header
+func lockedStatus(ctx context.Context, locked *auth.LockedError) error {
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(locked.RetryAfterSeconds())))
+	return status.Error(codes.ResourceExhausted, locked.Error())
+}
+
+// newUserResponse maps a user to its gRPC representation
+func newUserResponse(user *auth.User) *UserResponse {
 	return &UserResponse{
-		Id:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-	}, nil
+		Id:               user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		CreatedAt:        user.CreatedAt.Format(time.RFC3339),
+		Roles:            user.Roles,
+		EmailVerified:    user.IsEmailVerified(),
+		TwoFactorEnabled: user.HasTOTP(),
+	}
 }
\ No newline at end of file
@@ -0,0 +1,305 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/channel"
+	"github.com/ivmello/go-api-template/internal/middleware"
+	"github.com/ivmello/go-api-template/pkg/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the ChannelService gRPC server
+type Server struct {
+	UnimplementedChannelServiceServer
+	service *channel.Service
+}
+
+// NewServer creates a new channel gRPC server
+func NewServer(service *channel.Service) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// CreateChannel creates a new channel owned by the caller
+func (s *Server) CreateChannel(ctx context.Context, req *CreateChannelRequest) (*ChannelResponse, error) {
+	// Validate request
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Create channel
+	ch, err := s.service.Create(ctx, userID, req.Name, req.Description, req.Visibility)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrInvalidName) || errors.Is(err, channel.ErrInvalidVisibility) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, channel.ErrNameTaken) {
+			code = codes.AlreadyExists
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return newChannelResponse(ch), nil
+}
+
+// GetChannel returns a channel visible to the caller
+func (s *Server) GetChannel(ctx context.Context, req *GetChannelRequest) (*ChannelResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get channel
+	ch, err := s.service.Get(ctx, req.Id, userID)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return newChannelResponse(ch), nil
+}
+
+// ListChannels lists public channels and the caller's private channels
+func (s *Server) ListChannels(ctx context.Context, req *ListChannelsRequest) (*ListChannelsResponse, error) {
+	// Validate request
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get channels
+	page, err := s.service.List(ctx, channel.ListOptions{
+		UserID: userID,
+		Joined: req.Joined,
+		Limit:  int(req.PageSize),
+		Cursor: req.PageToken,
+	})
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrInvalidCursor) || errors.Is(err, channel.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Convert channels to response format
+	responses := make([]*ChannelResponse, len(page.Channels))
+	for i, ch := range page.Channels {
+		responses[i] = newChannelResponse(ch)
+	}
+
+	return &ListChannelsResponse{
+		Channels:      responses,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// JoinChannel makes the caller a member of a public channel
+func (s *Server) JoinChannel(ctx context.Context, req *JoinChannelRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Join channel
+	if err := s.service.Join(ctx, req.Id, userID); err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// LeaveChannel ends the caller's membership of a channel
+func (s *Server) LeaveChannel(ctx context.Context, req *LeaveChannelRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Leave channel
+	if err := s.service.Leave(ctx, req.Id, userID); err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrNotMember) {
+			code = codes.NotFound
+		} else if errors.Is(err, channel.ErrLastOwner) {
+			code = codes.FailedPrecondition
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// InviteMember adds a user to a channel on behalf of the caller
+func (s *Server) InviteMember(ctx context.Context, req *InviteMemberRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := validator.ValidateUUID(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a user ID")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Invite member
+	if err := s.service.Invite(ctx, req.Id, userID, req.UserId); err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrChannelNotFound) || errors.Is(err, auth.ErrUserNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, channel.ErrNotMember) || errors.Is(err, channel.ErrNotOwner) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// ListMembers lists the members of a channel visible to the caller
+func (s *Server) ListMembers(ctx context.Context, req *ListMembersRequest) (*ListMembersResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get members
+	page, err := s.service.ListMembers(ctx, req.Id, userID, int(req.PageSize), req.PageToken)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrInvalidCursor) || errors.Is(err, channel.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, channel.ErrChannelNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Convert members to response format
+	responses := make([]*MemberResponse, len(page.Members))
+	for i, member := range page.Members {
+		responses[i] = &MemberResponse{
+			UserId:   member.UserID,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt.Format(time.RFC3339),
+		}
+	}
+
+	return &ListMembersResponse{
+		Members:       responses,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// MarkChannelRead marks the messages of a channel as read by the caller
+func (s *Server) MarkChannelRead(ctx context.Context, req *MarkChannelReadRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	var readAt *time.Time
+	if req.ReadAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ReadAt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "read_at must be an RFC 3339 timestamp")
+		}
+		readAt = &t
+	}
+	if (req.MessageId == "") == (readAt == nil) {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of message_id and read_at is required")
+	}
+	if req.MessageId != "" {
+		if err := validator.ValidateUUID(req.MessageId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "message_id must be a message ID")
+		}
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Mark read
+	if err := s.service.MarkRead(ctx, req.Id, userID, req.MessageId, readAt); err != nil {
+		code := codes.Internal
+		if errors.Is(err, channel.ErrNotMember) || errors.Is(err, channel.ErrMessageNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// newChannelResponse maps a channel to its gRPC representation
+func newChannelResponse(ch *channel.Channel) *ChannelResponse {
+	response := &ChannelResponse{
+		Id:          ch.ID,
+		Name:        ch.Name,
+		Description: ch.Description,
+		Visibility:  ch.Visibility,
+		MemberCount: int32(ch.MemberCount),
+		CreatedAt:   ch.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   ch.UpdatedAt.Format(time.RFC3339),
+	}
+	if ch.CreatedBy != nil {
+		response.CreatedBy = *ch.CreatedBy
+	}
+	return response
+}
\ No newline at end of file
@@ -0,0 +1,350 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/conversation"
+	"github.com/ivmello/go-api-template/internal/middleware"
+	"github.com/ivmello/go-api-template/pkg/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the ConversationService gRPC server
+type Server struct {
+	UnimplementedConversationServiceServer
+	service *conversation.Service
+}
+
+// NewServer creates a new conversation gRPC server
+func NewServer(service *conversation.Service) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// CreateConversation starts a conversation between the caller and the
+// participants
+func (s *Server) CreateConversation(ctx context.Context, req *CreateConversationRequest) (*CreateConversationResponse, error) {
+	// Validate request
+	if len(req.ParticipantIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "participant_ids is required")
+	}
+	for _, id := range req.ParticipantIds {
+		if err := validator.ValidateUUID(id); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "participant_ids must be user IDs")
+		}
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Create conversation
+	conv, created, err := s.service.Create(ctx, userID, req.ParticipantIds, req.Kind, req.Title)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrNoParticipants) || errors.Is(err, conversation.ErrTooManyParticipants) ||
+			errors.Is(err, conversation.ErrInvalidKind) || errors.Is(err, conversation.ErrInvalidTitle) ||
+			errors.Is(err, conversation.ErrInvalidDirect) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, auth.ErrUserNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &CreateConversationResponse{
+		Conversation: newConversationResponse(conv),
+		Created:      created,
+	}, nil
+}
+
+// GetConversation returns a conversation the caller takes part in
+func (s *Server) GetConversation(ctx context.Context, req *GetConversationRequest) (*ConversationResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "id must be a conversation ID")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get conversation
+	conv, err := s.service.Get(ctx, req.Id, userID)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrConversationNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return newConversationResponse(conv), nil
+}
+
+// ListConversations lists the caller's conversations, most recently active
+// first
+func (s *Server) ListConversations(ctx context.Context, req *ListConversationsRequest) (*ListConversationsResponse, error) {
+	// Validate request
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get conversations
+	page, err := s.service.List(ctx, userID, int(req.PageSize), req.PageToken)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrInvalidCursor) || errors.Is(err, conversation.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Convert conversations to response format
+	responses := make([]*ConversationResponse, len(page.Conversations))
+	for i, conv := range page.Conversations {
+		responses[i] = newConversationResponse(conv)
+	}
+
+	return &ListConversationsResponse{
+		Conversations: responses,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// AddParticipants adds users to a group conversation
+func (s *Server) AddParticipants(ctx context.Context, req *AddParticipantsRequest) (*ConversationResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "id must be a conversation ID")
+	}
+	if len(req.UserIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_ids is required")
+	}
+	for _, id := range req.UserIds {
+		if err := validator.ValidateUUID(id); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "user_ids must be user IDs")
+		}
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Add participants
+	conv, err := s.service.AddParticipants(ctx, req.Id, userID, req.UserIds)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrTooManyParticipants) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, conversation.ErrConversationNotFound) || errors.Is(err, auth.ErrUserNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, conversation.ErrDirectConversation) {
+			code = codes.FailedPrecondition
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return newConversationResponse(conv), nil
+}
+
+// RemoveParticipant removes a participant from a group conversation
+func (s *Server) RemoveParticipant(ctx context.Context, req *RemoveParticipantRequest) (*EmptyResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "id must be a conversation ID")
+	}
+	if err := validator.ValidateUUID(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a user ID")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Remove participant
+	if err := s.service.RemoveParticipant(ctx, req.Id, userID, req.UserId); err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrConversationNotFound) || errors.Is(err, conversation.ErrNotParticipant) {
+			code = codes.NotFound
+		} else if errors.Is(err, conversation.ErrNotCreator) {
+			code = codes.PermissionDenied
+		} else if errors.Is(err, conversation.ErrDirectConversation) {
+			code = codes.FailedPrecondition
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// SendMessage sends a message to a conversation the caller takes part in
+func (s *Server) SendMessage(ctx context.Context, req *SendMessageRequest) (*MessageResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "id must be a conversation ID")
+	}
+	if req.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "content is required")
+	}
+	if len(req.Content) > 1000 {
+		return nil, status.Error(codes.InvalidArgument, "content must be less than 1000 characters")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Send message
+	msg, err := s.service.Send(ctx, req.Id, userID, req.Content)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrConversationNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return newMessageResponse(msg), nil
+}
+
+// ListMessages lists the messages of a conversation, newest first
+func (s *Server) ListMessages(ctx context.Context, req *ListMessagesRequest) (*ListMessagesResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "id must be a conversation ID")
+	}
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get messages
+	page, err := s.service.ListMessages(ctx, req.Id, userID, int(req.PageSize), req.PageToken)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrInvalidCursor) || errors.Is(err, conversation.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, conversation.ErrConversationNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Convert messages to response format
+	responses := make([]*MessageResponse, len(page.Messages))
+	for i, msg := range page.Messages {
+		responses[i] = newMessageResponse(msg)
+	}
+
+	return &ListMessagesResponse{
+		Messages:      responses,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// MarkRead moves the caller's last-read pointer of a conversation
+func (s *Server) MarkRead(ctx context.Context, req *MarkReadRequest) (*ConversationResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.Id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "id must be a conversation ID")
+	}
+	var readAt *time.Time
+	if req.ReadAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ReadAt)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "read_at must be an RFC 3339 timestamp")
+		}
+		readAt = &t
+	}
+	if (req.MessageId == "") == (readAt == nil) {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of message_id and read_at is required")
+	}
+	if req.MessageId != "" {
+		if err := validator.ValidateUUID(req.MessageId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "message_id must be a message ID")
+		}
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Mark read
+	conv, err := s.service.MarkRead(ctx, req.Id, userID, req.MessageId, readAt)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, conversation.ErrConversationNotFound) || errors.Is(err, conversation.ErrMessageNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return newConversationResponse(conv), nil
+}
+
+// newConversationResponse maps a conversation to its gRPC representation
+func newConversationResponse(conv *conversation.Conversation) *ConversationResponse {
+	response := &ConversationResponse{
+		Id:             conv.ID,
+		Kind:           conv.Kind,
+		Title:          conv.Title,
+		Participants:   make([]*ParticipantResponse, len(conv.Participants)),
+		UnreadCount:    int32(conv.UnreadCount),
+		LastActivityAt: conv.LastActivityAt.Format(time.RFC3339),
+		CreatedAt:      conv.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      conv.UpdatedAt.Format(time.RFC3339),
+	}
+	if conv.CreatedBy != nil {
+		response.CreatedBy = *conv.CreatedBy
+	}
+	for i, participant := range conv.Participants {
+		response.Participants[i] = &ParticipantResponse{
+			UserId:   participant.UserID,
+			JoinedAt: participant.JoinedAt.Format(time.RFC3339),
+		}
+		if participant.LastReadMessageID != nil {
+			response.Participants[i].LastReadMessageId = *participant.LastReadMessageID
+		}
+	}
+	return response
+}
+
+// newMessageResponse maps a conversation message to its gRPC representation
+func newMessageResponse(msg *conversation.Message) *MessageResponse {
+	return &MessageResponse{
+		Id:             msg.ID,
+		ConversationId: msg.ConversationID,
+		UserId:         msg.UserID,
+		Content:        msg.Content,
+		CreatedAt:      msg.CreatedAt.Format(time.RFC3339),
+	}
+}
\ No newline at end of file
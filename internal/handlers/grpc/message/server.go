@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/ivmello/go-api-template/internal/core/message"
+	"github.com/ivmello/go-api-template/internal/core/reaction"
 	"github.com/ivmello/go-api-template/internal/middleware"
+	"github.com/ivmello/go-api-template/pkg/validator"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -14,13 +16,15 @@ import (
 // Server implements the MessageService gRPC server
 type Server struct {
 	UnimplementedMessageServiceServer
-	service *message.Service
+	service   *message.Service
+	reactions *reaction.Service
 }
 
 // NewServer creates a new message gRPC server
-func NewServer(service *message.Service) *Server {
+func NewServer(service *message.Service, reactions *reaction.Service) *Server {
 	return &Server{
-		service: service,
+		service:   service,
+		reactions: reactions,
 	}
 }
 
@@ -30,6 +34,14 @@ func (s *Server) CreateMessage(ctx context.Context, req *CreateMessageRequest) (
 	if req.Content == "" {
 		return nil, status.Error(codes.InvalidArgument, "content is required")
 	}
+	if err := validator.ValidateUUID(req.ChannelId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "channel_id must be a channel ID")
+	}
+	if req.ParentId != "" {
+		if err := validator.ValidateUUID(req.ParentId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "parent_id must be a message ID")
+		}
+	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, err := middleware.GetUserIDFromContext(ctx)
@@ -38,19 +50,19 @@ func (s *Server) CreateMessage(ctx context.Context, req *CreateMessageRequest) (
 	}
 
 	// Create message
-	msg, err := s.service.Create(ctx, userID, req.Content)
+	msg, err := s.service.Create(ctx, req.ChannelId, userID, req.Content, req.ParentId)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		code := codes.Internal
+		if errors.Is(err, message.ErrParentNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
 	}
 
 	// Return message
-	return &MessageResponse{
-		Id:        msg.ID,
-		UserId:    msg.UserID,
-		Content:   msg.Content,
-		CreatedAt: msg.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: msg.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return newMessageResponse(msg, nil), nil
 }
 
 // GetMessage returns a message by ID
@@ -60,24 +72,32 @@ func (s *Server) GetMessage(ctx context.Context, req *GetMessageRequest) (*Messa
 		return nil, status.Error(codes.InvalidArgument, "id is required")
 	}
 
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
 	// Get message
-	msg, err := s.service.GetByID(ctx, req.Id)
+	msg, err := s.service.Get(ctx, req.Id, userID)
 	if err != nil {
 		code := codes.Internal
 		if errors.Is(err, message.ErrMessageNotFound) {
 			code = codes.NotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
 		}
 		return nil, status.Error(code, err.Error())
 	}
 
+	// Get reactions
+	summaries, err := s.reactionSummaries(ctx, []*message.Message{msg})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
 	// Return message
-	return &MessageResponse{
-		Id:        msg.ID,
-		UserId:    msg.UserID,
-		Content:   msg.Content,
-		CreatedAt: msg.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: msg.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return newMessageResponse(msg, summaries[msg.ID]), nil
 }
 
 // UpdateMessage updates a message
@@ -97,7 +117,7 @@ func (s *Server) UpdateMessage(ctx context.Context, req *UpdateMessageRequest) (
 	}
 
 	// Update message
-	err = s.service.Update(ctx, req.Id, userID, req.Content)
+	err = s.service.Update(ctx, req.Id, userID, middleware.GetRolesFromContext(ctx), req.Content)
 	if err != nil {
 		code := codes.Internal
 		if errors.Is(err, message.ErrMessageNotFound) {
@@ -125,7 +145,7 @@ func (s *Server) DeleteMessage(ctx context.Context, req *DeleteMessageRequest) (
 	}
 
 	// Delete message
-	err = s.service.Delete(ctx, req.Id, userID)
+	err = s.service.Delete(ctx, req.Id, userID, middleware.GetRolesFromContext(ctx))
 	if err != nil {
 		code := codes.Internal
 		if errors.Is(err, message.ErrMessageNotFound) {
@@ -139,27 +159,511 @@ func (s *Server) DeleteMessage(ctx context.Context, req *DeleteMessageRequest) (
 	return &EmptyResponse{}, nil
 }
 
-// ListMessages lists all messages
+// ListMessages lists a page of messages
 func (s *Server) ListMessages(ctx context.Context, req *ListMessagesRequest) (*ListMessagesResponse, error) {
+	// Validate request
+	if err := validator.ValidateUUID(req.ChannelId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "channel_id must be a channel ID")
+	}
+	if req.UserId != "" {
+		if err := validator.ValidateUUID(req.UserId); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "user_id must be a user ID")
+		}
+	}
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	createdAfter, err := parseOptionalTime(req.CreatedAfter)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "created_after must be an RFC 3339 timestamp")
+	}
+	createdBefore, err := parseOptionalTime(req.CreatedBefore)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "created_before must be an RFC 3339 timestamp")
+	}
+	if createdAfter != nil && createdBefore != nil && !createdAfter.Before(*createdBefore) {
+		return nil, status.Error(codes.InvalidArgument, "created_after must be before created_before")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
 	// Get messages
-	msgs, err := s.service.GetAll(ctx)
+	page, err := s.service.List(ctx, userID, message.ListOptions{
+		ChannelID:     req.ChannelId,
+		UserID:        req.UserId,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Order:         req.Order,
+		Limit:         int(req.PageSize),
+		Cursor:        req.PageToken,
+	})
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrInvalidCursor) || errors.Is(err, message.ErrInvalidSortOrder) || errors.Is(err, message.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Get reactions
+	summaries, err := s.reactionSummaries(ctx, page.Messages)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	// Convert messages to response format
-	responses := make([]*MessageResponse, len(msgs))
-	for i, msg := range msgs {
-		responses[i] = &MessageResponse{
-			Id:        msg.ID,
-			UserId:    msg.UserID,
-			Content:   msg.Content,
-			CreatedAt: msg.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: msg.UpdatedAt.Format(time.RFC3339),
+	responses := make([]*MessageResponse, len(page.Messages))
+	for i, msg := range page.Messages {
+		responses[i] = newMessageResponse(msg, summaries[msg.ID])
+	}
+
+	return &ListMessagesResponse{
+		Messages:      responses,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// SearchMessages runs a full-text search over messages
+func (s *Server) SearchMessages(ctx context.Context, req *SearchMessagesRequest) (*SearchMessagesResponse, error) {
+	// Validate request
+	if req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Search messages
+	page, err := s.service.Search(ctx, userID, req.Query, int(req.PageSize), req.PageToken)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrInvalidCursor) || errors.Is(err, message.ErrInvalidSearchQuery) || errors.Is(err, message.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Get reactions
+	messages := make([]*message.Message, len(page.Results))
+	for i, result := range page.Results {
+		messages[i] = result.Message
+	}
+	summaries, err := s.reactionSummaries(ctx, messages)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Convert results to response format
+	results := make([]*SearchResult, len(page.Results))
+	for i, result := range page.Results {
+		results[i] = &SearchResult{
+			Message: newMessageResponse(result.Message, summaries[result.Message.ID]),
+			Rank:    result.Rank,
+			Snippet: result.Snippet,
+		}
+	}
+
+	return &SearchMessagesResponse{
+		Results:       results,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+// ListTrash lists the current user's deleted messages
+func (s *Server) ListTrash(ctx context.Context, req *ListTrashRequest) (*ListMessagesResponse, error) {
+	// Validate request
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get deleted messages
+	page, err := s.service.ListTrash(ctx, userID, int(req.PageSize), req.PageToken)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrInvalidCursor) || errors.Is(err, message.ErrInvalidPageSize) {
+			code = codes.InvalidArgument
 		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Get reactions
+	summaries, err := s.reactionSummaries(ctx, page.Messages)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Convert messages to response format
+	responses := make([]*MessageResponse, len(page.Messages))
+	for i, msg := range page.Messages {
+		responses[i] = newMessageResponse(msg, summaries[msg.ID])
 	}
 
 	return &ListMessagesResponse{
-		Messages: responses,
+		Messages:      responses,
+		NextPageToken: page.NextCursor,
 	}, nil
+}
+
+// RestoreMessage takes a message out of the trash
+func (s *Server) RestoreMessage(ctx context.Context, req *RestoreMessageRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Restore message
+	err = s.service.Restore(ctx, req.Id, userID, middleware.GetRolesFromContext(ctx))
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrMessageNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, message.ErrForbidden) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// ListMessageRevisions lists the edit history of a message
+func (s *Server) ListMessageRevisions(ctx context.Context, req *ListMessageRevisionsRequest) (*ListMessageRevisionsResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get revisions
+	revisions, err := s.service.ListRevisions(ctx, req.Id, userID)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrMessageNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Convert revisions to response format
+	responses := make([]*RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = newRevisionResponse(revision)
+	}
+
+	return &ListMessageRevisionsResponse{
+		Revisions: responses,
+	}, nil
+}
+
+// DiffMessageRevisions compares two revisions of a message
+func (s *Server) DiffMessageRevisions(ctx context.Context, req *DiffMessageRevisionsRequest) (*DiffMessageRevisionsResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Diff revisions
+	result, err := s.service.DiffRevisions(ctx, req.Id, userID, int(req.From), int(req.To))
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrMessageNotFound) || errors.Is(err, message.ErrRevisionNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, message.ErrInvalidRevision) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Convert diff to response format
+	lines := make([]*DiffLine, len(result.Lines))
+	for i, line := range result.Lines {
+		lines[i] = &DiffLine{
+			Op:   string(line.Op),
+			Text: line.Text,
+		}
+	}
+
+	return &DiffMessageRevisionsResponse{
+		From:  newRevisionResponse(result.From),
+		To:    newRevisionResponse(result.To),
+		Lines: lines,
+	}, nil
+}
+
+// GetThread returns a message and its replies at any depth
+func (s *Server) GetThread(ctx context.Context, req *GetThreadRequest) (*ThreadResponse, error) {
+	// Validate request
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get thread
+	thread, err := s.service.GetThread(ctx, req.Id, userID)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrMessageNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	// Get reactions
+	nodes := thread.Flatten()
+	messages := make([]*message.Message, len(nodes))
+	for i, node := range nodes {
+		messages[i] = node.Message
+	}
+	summaries, err := s.reactionSummaries(ctx, messages)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Convert thread to response format
+	response := &ThreadResponse{Truncated: thread.Truncated}
+	if req.Flat {
+		response.Messages = make([]*ThreadNode, len(nodes))
+		for i, node := range nodes {
+			response.Messages[i] = &ThreadNode{
+				Message: newMessageResponse(node.Message, summaries[node.Message.ID]),
+				Depth:   int32(node.Depth),
+			}
+		}
+	} else {
+		response.Root = newThreadNode(thread.Root, summaries)
+	}
+
+	return response, nil
+}
+
+// AddReaction reacts to a message with an emoji
+func (s *Server) AddReaction(ctx context.Context, req *ReactionRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.MessageId == "" || req.Emoji == "" {
+		return nil, status.Error(codes.InvalidArgument, "message_id and emoji are required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Add reaction
+	if err := s.reactions.Add(ctx, req.MessageId, userID, req.Emoji); err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrMessageNotFound) {
+			code = codes.NotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			code = codes.PermissionDenied
+		} else if errors.Is(err, reaction.ErrInvalidEmoji) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// RemoveReaction takes back the caller's reaction to a message
+func (s *Server) RemoveReaction(ctx context.Context, req *ReactionRequest) (*EmptyResponse, error) {
+	// Validate request
+	if req.MessageId == "" || req.Emoji == "" {
+		return nil, status.Error(codes.InvalidArgument, "message_id and emoji are required")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Remove reaction
+	if err := s.reactions.Remove(ctx, req.MessageId, userID, req.Emoji); err != nil {
+		code := codes.Internal
+		if errors.Is(err, reaction.ErrReactionNotFound) {
+			code = codes.NotFound
+		}
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &EmptyResponse{}, nil
+}
+
+// WatchMessages streams message events as they happen. Clients resume after
+// a disconnect by passing the ID of the last event they received.
+func (s *Server) WatchMessages(req *WatchMessagesRequest, stream MessageService_WatchMessagesServer) error {
+	ctx := stream.Context()
+
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Subscribe to events from the caller's channels
+	sub, err := s.service.Watch(ctx, userID, req.LastEventId)
+	if err != nil {
+		code := codes.Internal
+		if errors.Is(err, message.ErrInvalidEventID) {
+			code = codes.InvalidArgument
+		} else if errors.Is(err, message.ErrEventsExpired) {
+			code = codes.OutOfRange
+		} else if errors.Is(err, message.ErrEventStreamClosed) {
+			code = codes.Unavailable
+		}
+		return status.Error(code, err.Error())
+	}
+
+	// Forward events until the client goes away or the subscription ends
+	for event := range sub.Events() {
+		if err := stream.Send(newMessageEvent(event)); err != nil {
+			return err
+		}
+	}
+
+	err = sub.Err()
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	// Lagging or shutting down, the client should resume from its last event
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+// reactionSummaries loads the reactions to the messages, marking those of
+// the caller
+func (s *Server) reactionSummaries(ctx context.Context, messages []*message.Message) (map[string]*reaction.Summary, error) {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+	viewerID, _ := middleware.GetUserIDFromContext(ctx)
+	return s.reactions.Summaries(ctx, ids, viewerID)
+}
+
+// newMessageResponse maps a message and its reactions, which may be nil, to
+// its gRPC representation
+func newMessageResponse(msg *message.Message, reactions *reaction.Summary) *MessageResponse {
+	response := &MessageResponse{
+		Id:            msg.ID,
+		ChannelId:     msg.ChannelID,
+		UserId:        msg.UserID,
+		Content:       msg.Content,
+		Edited:        msg.IsEdited(),
+		RevisionCount: int32(msg.RevisionCount),
+		ReplyCount:    int32(msg.ReplyCount),
+		CreatedAt:     msg.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     msg.UpdatedAt.Format(time.RFC3339),
+	}
+	if msg.ParentID != nil {
+		response.ParentId = *msg.ParentID
+	}
+	if msg.DeletedAt != nil {
+		response.DeletedAt = msg.DeletedAt.Format(time.RFC3339)
+	}
+	if reactions != nil {
+		for _, count := range reactions.Counts {
+			response.Reactions = append(response.Reactions, &ReactionCount{
+				Emoji: count.Emoji,
+				Count: int32(count.Count),
+			})
+		}
+		response.MyReactions = reactions.Mine
+	}
+	return response
+}
+
+// newMessageEvent maps a message event to its gRPC representation
+func newMessageEvent(event *message.Event) *MessageEvent {
+	return &MessageEvent{
+		Id:         event.ID,
+		Type:       string(event.Type),
+		Message:    newMessageResponse(event.Message, nil),
+		OccurredAt: event.OccurredAt.Format(time.RFC3339),
+	}
+}
+
+// newThreadNode maps a thread node and its replies to their gRPC
+// representation
+func newThreadNode(node *message.ThreadNode, summaries map[string]*reaction.Summary) *ThreadNode {
+	response := &ThreadNode{
+		Message: newMessageResponse(node.Message, summaries[node.Message.ID]),
+		Depth:   int32(node.Depth),
+		Replies: make([]*ThreadNode, len(node.Replies)),
+	}
+	for i, reply := range node.Replies {
+		response.Replies[i] = newThreadNode(reply, summaries)
+	}
+	return response
+}
+
+// newRevisionResponse maps a revision to its gRPC representation
+func newRevisionResponse(revision *message.Revision) *RevisionResponse {
+	response := &RevisionResponse{
+		Revision:  int32(revision.Revision),
+		Content:   revision.Content,
+		CreatedAt: revision.CreatedAt.Format(time.RFC3339),
+	}
+	if revision.EditedBy != nil {
+		response.EditedBy = *revision.EditedBy
+	}
+	return response
+}
+
+// parseOptionalTime parses an RFC 3339 timestamp, returning nil when empty
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
 }
\ No newline at end of file
@@ -0,0 +1,51 @@
+package unread
+
+import (
+	"context"
+
+	"github.com/ivmello/go-api-template/internal/core/unread"
+	"github.com/ivmello/go-api-template/internal/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the UnreadService gRPC server
+type Server struct {
+	UnimplementedUnreadServiceServer
+	service *unread.Service
+}
+
+// NewServer creates a new unread gRPC server
+func NewServer(service *unread.Service) *Server {
+	return &Server{
+		service: service,
+	}
+}
+
+// GetUnreadCounts returns the caller's unread message counts
+func (s *Server) GetUnreadCounts(ctx context.Context, req *GetUnreadCountsRequest) (*UnreadCountsResponse, error) {
+	// Get user ID from context (set by auth middleware)
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "not authenticated")
+	}
+
+	// Get unread counts
+	summary, err := s.service.Summary(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Convert counts to response format
+	response := &UnreadCountsResponse{Total: int32(summary.Total)}
+	for _, count := range summary.Counts {
+		item := &UnreadCount{Id: count.Target.ID, Unread: int32(count.Unread)}
+		if count.Target.Kind == unread.KindChannel {
+			response.Channels = append(response.Channels, item)
+		} else {
+			response.Conversations = append(response.Conversations, item)
+		}
+	}
+
+	return response, nil
+}
\ No newline at end of file
@@ -0,0 +1,156 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/core/apikey"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+)
+
+// Handler handles API key HTTP requests
+type Handler struct {
+	service *apikey.Service
+}
+
+// NewHandler creates a new API key handler
+func NewHandler(service *apikey.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Create creates a new API key
+// @Summary Create API key
+// @Description Create a personal API key scoped to messages:read and/or messages:write. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body httpTransport.CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} httpTransport.CreatedAPIKeyResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/api-keys [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req httpTransport.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Create API key
+	key, plaintext, err := h.service.Create(c.Request.Context(), userID.(string), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, apikey.ErrInvalidScope) || errors.Is(err, apikey.ErrInvalidExpiry) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, httpTransport.CreatedAPIKeyResponse{
+		APIKeyResponse: newAPIKeyResponse(key),
+		Key:            plaintext,
+	})
+}
+
+// List lists the current user's API keys
+// @Summary List API keys
+// @Description List the current user's API keys, including revoked and expired ones
+// @Tags api-keys
+// @Produce json
+// @Security Bearer
+// @Success 200 {array} httpTransport.APIKeyResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/api-keys [get]
+func (h *Handler) List(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	keys, err := h.service.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := make([]httpTransport.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		response[i] = newAPIKeyResponse(key)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke revokes an API key
+// @Summary Revoke API key
+// @Description Revoke one of the current user's API keys
+// @Tags api-keys
+// @Produce json
+// @Security Bearer
+// @Param id path string true "API key ID"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *Handler) Revoke(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Revoke API key
+	if err := h.service.Revoke(c.Request.Context(), id, userID.(string)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, apikey.ErrAPIKeyNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "API key revoked successfully",
+	})
+}
+
+// newAPIKeyResponse maps an API key to its response representation
+func newAPIKeyResponse(key *apikey.APIKey) httpTransport.APIKeyResponse {
+	return httpTransport.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
\ No newline at end of file
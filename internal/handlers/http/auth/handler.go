@@ -2,11 +2,14 @@ package auth
 
 import (
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ivmello/go-api-template/internal/core/auth"
-	"github.com/ivmello/go-api-template/internal/transport/http"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+	pkgAuth "github.com/ivmello/go-api-template/pkg/auth"
 )
 
 // Handler handles authentication HTTP requests
@@ -27,22 +30,22 @@ func NewHandler(service *auth.Service) *Handler {
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body http.RegisterRequest true "User registration data"
-// @Success 201 {object} http.UserResponse
-// @Failure 400 {object} http.ErrorResponse
-// @Failure 409 {object} http.ErrorResponse
-// @Failure 500 {object} http.ErrorResponse
+// @Param request body httpTransport.RegisterRequest true "User registration data"
+// @Success 201 {object} httpTransport.UserResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
 // @Router /api/v1/auth/register [post]
 func (h *Handler) Register(c *gin.Context) {
-	var req http.RegisterRequest
+	var req httpTransport.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, http.ErrorResponse{Error: "Invalid request format"})
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
 		return
 	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, http.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -53,61 +56,534 @@ func (h *Handler) Register(c *gin.Context) {
 		if errors.Is(err, auth.ErrEmailAlreadyExists) {
 			status = http.StatusConflict
 		}
-		c.JSON(status, http.ErrorResponse{Error: err.Error()})
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// Return user
-	c.JSON(http.StatusCreated, http.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt,
-	})
+	c.JSON(http.StatusCreated, newUserResponse(user))
 }
 
 // Login handles user login
 // @Summary Login user
-// @Description Login with email and password to get a JWT token
+// @Description Login with email and password to get a JWT token. Accounts with two-factor authentication get a challenge token to complete at /auth/2fa/verify instead.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body http.LoginRequest true "User login data"
-// @Success 200 {object} http.TokenResponse
-// @Failure 400 {object} http.ErrorResponse
-// @Failure 401 {object} http.ErrorResponse
-// @Failure 500 {object} http.ErrorResponse
+// @Param request body httpTransport.LoginRequest true "User login data"
+// @Success 200 {object} httpTransport.TokenResponse
+// @Success 202 {object} httpTransport.MFAChallengeResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 429 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
 // @Router /api/v1/auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
-	var req http.LoginRequest
+	var req httpTransport.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, http.ErrorResponse{Error: "Invalid request format"})
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
 		return
 	}
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		c.JSON(http.StatusBadRequest, http.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// Login user
-	token, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
+	result, err := h.service.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
+		var locked *auth.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			c.JSON(http.StatusTooManyRequests, httpTransport.ErrorResponse{Error: err.Error()})
+			return
+		}
+
 		status := http.StatusInternalServerError
 		if errors.Is(err, auth.ErrInvalidCredentials) {
 			status = http.StatusUnauthorized
+		} else if errors.Is(err, auth.ErrEmailNotVerified) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Ask for the second factor
+	if result.Challenge != nil {
+		c.JSON(http.StatusAccepted, httpTransport.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.Challenge.Token,
+			ExpiresAt:   result.Challenge.ExpiresAt,
+		})
+		return
+	}
+
+	// Return tokens
+	c.JSON(http.StatusOK, httpTransport.TokenResponse{
+		Token:        result.Tokens.AccessToken,
+		RefreshToken: result.Tokens.RefreshToken,
+		ExpiresAt:    result.Tokens.ExpiresAt,
+	})
+}
+
+// VerifyMFA handles the second step of a two-factor login
+// @Summary Verify two-factor code
+// @Description Exchange the login challenge token and a TOTP or recovery code for a JWT token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body httpTransport.VerifyMFARequest true "Challenge token and code"
+// @Success 200 {object} httpTransport.TokenResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 429 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *Handler) VerifyMFA(c *gin.Context) {
+	var req httpTransport.VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Verify second factor
+	tokens, err := h.service.VerifyMFA(c.Request.Context(), req.MFAToken, req.Code, c.ClientIP())
+	if err != nil {
+		var locked *auth.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			c.JSON(http.StatusTooManyRequests, httpTransport.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidMFAToken) || errors.Is(err, auth.ErrInvalidMFACode) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Return tokens
+	c.JSON(http.StatusOK, httpTransport.TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
+	})
+}
+
+// EnrollTOTP handles starting two-factor enrollment
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and otpauth:// URI for an authenticator app. It is inactive until confirmed.
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} httpTransport.TOTPEnrollmentResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Start enrollment
+	enrollment, err := h.service.EnrollTOTP(c.Request.Context(), userID.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrTOTPAlreadyEnabled) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// ConfirmTOTP handles confirming two-factor enrollment
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app. The returned recovery codes are shown only once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body httpTransport.ConfirmTOTPRequest true "TOTP code"
+// @Success 200 {object} httpTransport.RecoveryCodesResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/2fa/confirm [post]
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	var req httpTransport.ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Enable two-factor authentication
+	codes, err := h.service.ConfirmTOTP(c.Request.Context(), userID.(string), req.Code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidMFACode) || errors.Is(err, auth.ErrTOTPNotEnrolled) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, auth.ErrTOTPAlreadyEnabled) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP handles turning off two-factor authentication
+// @Summary Disable two-factor authentication
+// @Description Turn off two-factor authentication with the account password and a TOTP or recovery code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body httpTransport.DisableTOTPRequest true "Password and code"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *Handler) DisableTOTP(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	var req httpTransport.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Disable two-factor authentication
+	if err := h.service.DisableTOTP(c.Request.Context(), userID.(string), req.Password, req.Code); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrTOTPNotEnabled) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, auth.ErrInvalidMFACode) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// Refresh handles access token renewal
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and a rotated refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body httpTransport.RefreshRequest true "Refresh token"
+// @Success 200 {object} httpTransport.TokenResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	var req httpTransport.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Rotate refresh token
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			status = http.StatusUnauthorized
 		}
-		c.JSON(status, http.ErrorResponse{Error: err.Error()})
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	// Return token
-	c.JSON(http.StatusOK, http.TokenResponse{
-		Token: token,
+	// Return tokens
+	c.JSON(http.StatusOK, httpTransport.TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresAt:    tokens.ExpiresAt,
 	})
 }
 
+// Logout handles user logout
+// @Summary Logout user
+// @Description Revoke the current access token and, optionally, the refresh token issued with it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body httpTransport.LogoutRequest false "Refresh token to revoke"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	var req httpTransport.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Get claims from context (set by auth middleware)
+	claims, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Revoke tokens
+	if err := h.service.Logout(c.Request.Context(), claims.(*pkgAuth.Claims), req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Logged out successfully",
+	})
+}
+
+// LogoutAll handles logging out of every session
+// @Summary Logout all sessions
+// @Description Revoke every access and refresh token issued to the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/logout-all [post]
+func (h *Handler) LogoutAll(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Revoke all tokens
+	if err := h.service.LogoutAll(c.Request.Context(), userID.(string)); err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Logged out of all sessions successfully",
+	})
+}
+
+// VerifyEmail handles email verification links
+// @Summary Verify email
+// @Description Confirm an email address with the token from the verification email
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/verify [get]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "token is required"})
+		return
+	}
+
+	// Verify email
+	if err := h.service.VerifyEmail(c.Request.Context(), token); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidVerifyToken) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Email verified successfully",
+	})
+}
+
+// ResendVerification handles requests for a new verification email
+// @Summary Resend verification email
+// @Description Send a new verification link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body httpTransport.ResendVerificationRequest true "Account email"
+// @Success 202 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/verify/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req httpTransport.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Resend verification
+	if err := h.service.ResendVerificationEmail(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, httpTransport.SuccessResponse{
+		Message: "If the email is registered and unverified, a verification link has been sent",
+	})
+}
+
+// ForgotPassword handles password reset requests
+// @Summary Request password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body httpTransport.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req httpTransport.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Request reset
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, httpTransport.SuccessResponse{
+		Message: "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password using a reset token. All existing sessions are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body httpTransport.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req httpTransport.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Reset password
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidResetToken) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Password reset successfully",
+	})
+}
+
+// JWKS publishes the public token verification keys
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens issued by this service
+// @Tags auth
+// @Produce json
+// @Success 200 {object} pkgAuth.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.service.JWKS())
+}
+
 // Me returns the current user
 // @Summary Get current user
 // @Description Get details of the currently authenticated user
@@ -115,15 +591,15 @@ func (h *Handler) Login(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security Bearer
-// @Success 200 {object} http.UserResponse
-// @Failure 401 {object} http.ErrorResponse
-// @Failure 500 {object} http.ErrorResponse
+// @Success 200 {object} httpTransport.UserResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
 // @Router /api/v1/auth/me [get]
 func (h *Handler) Me(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, http.ErrorResponse{Error: "Not authenticated"})
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
 		return
 	}
 
@@ -134,15 +610,71 @@ func (h *Handler) Me(c *gin.Context) {
 		if errors.Is(err, auth.ErrUserNotFound) {
 			status = http.StatusNotFound
 		}
-		c.JSON(status, http.ErrorResponse{Error: err.Error()})
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// Return user
-	c.JSON(http.StatusOK, http.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt,
-	})
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// SetRoles replaces the roles of a user
+// @Summary Set user roles
+// @Description Replace the roles of a user (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID"
+// @Param request body httpTransport.SetRolesRequest true "Roles to assign"
+// @Success 200 {object} httpTransport.UserResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/admin/users/{id}/roles [put]
+func (h *Handler) SetRoles(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.SetRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Update roles
+	user, err := h.service.SetUserRoles(c.Request.Context(), id, req.Roles)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrInvalidRole) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, auth.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Return user
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// newUserResponse maps a user to its response representation
+func newUserResponse(user *auth.User) httpTransport.UserResponse {
+	return httpTransport.UserResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		TwoFactor:     user.HasTOTP(),
+		Name:          user.Name,
+		Roles:         user.Roles,
+		CreatedAt:     user.CreatedAt,
+	}
 }
\ No newline at end of file
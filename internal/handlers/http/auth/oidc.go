@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+)
+
+// oidcStateCookie holds the state of the OIDC login started by the browser
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler handles external OpenID Connect login HTTP requests
+type OIDCHandler struct {
+	service *auth.OIDCService
+}
+
+// NewOIDCHandler creates a new OIDC login handler
+func NewOIDCHandler(service *auth.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		service: service,
+	}
+}
+
+// Login redirects to an external identity provider
+// @Summary Sign in with an identity provider
+// @Description Start an OpenID Connect authorization code flow with PKCE by redirecting to the provider. The login state is bound to the browser with a cookie.
+// @Tags auth
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/oidc/{provider}/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	login, err := h.service.StartLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, auth.ErrUnknownProvider) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Bind the state to this browser for the callback
+	setStateCookie(c, strings.TrimSuffix(c.Request.URL.Path, "/login"), login.State, 0)
+	c.Redirect(http.StatusFound, login.URL)
+}
+
+// Callback completes a login with an external identity provider
+// @Summary Identity provider callback
+// @Description Exchange the authorization code returned by the provider for an access token and a refresh token. The state must match the cookie set when the login started. Accounts are linked by verified email or created on first sign in. Returns 202 with a challenge token when two-factor authentication is enabled.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} httpTransport.TokenResponse
+// @Success 202 {object} httpTransport.MFAChallengeResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/auth/oidc/{provider}/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	// The provider reports denied or failed authorizations in the query
+	if providerErr := c.Query("error"); providerErr != "" {
+		message := providerErr
+		if description := c.Query("error_description"); description != "" {
+			message += ": " + description
+		}
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: message})
+		return
+	}
+
+	// Validate request
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "code and state are required"})
+		return
+	}
+
+	// The state cookie is only valid for one callback
+	browserState, _ := c.Cookie(oidcStateCookie)
+	setStateCookie(c, strings.TrimSuffix(c.Request.URL.Path, "/callback"), "", -1)
+
+	// Login user
+	result, err := h.service.CompleteLogin(c.Request.Context(), c.Param("provider"), state, browserState, code)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, auth.ErrUnknownProvider):
+			status = http.StatusNotFound
+		case errors.Is(err, auth.ErrInvalidOIDCState), errors.Is(err, auth.ErrOIDCEmailRequired):
+			status = http.StatusBadRequest
+		case errors.Is(err, auth.ErrOIDCLoginFailed):
+			status = http.StatusUnauthorized
+		case errors.Is(err, auth.ErrEmailNotVerified):
+			status = http.StatusForbidden
+		case errors.Is(err, auth.ErrOIDCAccountExists):
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Ask for the second factor
+	if result.Challenge != nil {
+		c.JSON(http.StatusAccepted, httpTransport.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.Challenge.Token,
+			ExpiresAt:   result.Challenge.ExpiresAt,
+		})
+		return
+	}
+
+	// Return tokens
+	c.JSON(http.StatusOK, httpTransport.TokenResponse{
+		Token:        result.Tokens.AccessToken,
+		RefreshToken: result.Tokens.RefreshToken,
+		ExpiresAt:    result.Tokens.ExpiresAt,
+	})
+}
+
+// setStateCookie sets or, with a negative maxAge, clears the OIDC state
+// cookie. It is scoped to the provider's login and callback paths and sent
+// on the top-level redirect back from the provider.
+func setStateCookie(c *gin.Context, path, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, path, "", secure, true)
+}
\ No newline at end of file
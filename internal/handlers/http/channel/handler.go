@@ -0,0 +1,448 @@
+package channel
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/channel"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+)
+
+// Handler handles channel HTTP requests
+type Handler struct {
+	service *channel.Service
+}
+
+// NewHandler creates a new channel handler
+func NewHandler(service *channel.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Create creates a new channel
+// @Summary Create channel
+// @Description Create a public or private channel. The current user becomes its owner.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param request body httpTransport.CreateChannelRequest true "Channel data"
+// @Success 201 {object} httpTransport.ChannelResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req httpTransport.CreateChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Create channel
+	ch, err := h.service.Create(c.Request.Context(), userID.(string), req.Name, req.Description, req.Visibility)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrInvalidName) || errors.Is(err, channel.ErrInvalidVisibility) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, channel.ErrNameTaken) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newChannelResponse(ch))
+}
+
+// List retrieves a page of channels
+// @Summary List channels
+// @Description List public channels and the private channels the current user is a member of, ordered by name. Set joined to only list the current user's channels.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param joined query bool false "Only channels the current user is a member of"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.ChannelListResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels [get]
+func (h *Handler) List(c *gin.Context) {
+	var req httpTransport.ListChannelsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get channels
+	page, err := h.service.List(c.Request.Context(), channel.ListOptions{
+		UserID: userID.(string),
+		Joined: req.Joined,
+		Limit:  req.Limit,
+		Cursor: req.Cursor,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrInvalidCursor) || errors.Is(err, channel.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.ChannelListResponse{
+		Channels:   make([]httpTransport.ChannelResponse, len(page.Channels)),
+		NextCursor: page.NextCursor,
+	}
+	for i, ch := range page.Channels {
+		response.Channels[i] = newChannelResponse(ch)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get retrieves a single channel by ID
+// @Summary Get channel by ID
+// @Description Get a public channel, or a private channel the current user is a member of
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Channel ID"
+// @Success 200 {object} httpTransport.ChannelResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get channel
+	ch, err := h.service.Get(c.Request.Context(), id, userID.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newChannelResponse(ch))
+}
+
+// Join joins a public channel
+// @Summary Join channel
+// @Description Become a member of a public channel. Private channels can only be joined by invitation.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Channel ID"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels/{id}/join [post]
+func (h *Handler) Join(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Join channel
+	if err := h.service.Join(c.Request.Context(), id, userID.(string)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Joined channel successfully",
+	})
+}
+
+// Leave leaves a channel
+// @Summary Leave channel
+// @Description End the current user's membership of a channel. The last owner can't leave while other members remain.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Channel ID"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels/{id}/leave [post]
+func (h *Handler) Leave(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Leave channel
+	if err := h.service.Leave(c.Request.Context(), id, userID.(string)); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrNotMember) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, channel.ErrLastOwner) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Left channel successfully",
+	})
+}
+
+// Invite adds a user to a channel
+// @Summary Invite member
+// @Description Add a user to a channel. Any member can invite to a public channel; only owners can invite to a private one.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Channel ID"
+// @Param request body httpTransport.InviteMemberRequest true "User to invite"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels/{id}/members [post]
+func (h *Handler) Invite(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Invite member
+	if err := h.service.Invite(c.Request.Context(), id, userID.(string), req.UserID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrChannelNotFound) || errors.Is(err, auth.ErrUserNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, channel.ErrNotMember) || errors.Is(err, channel.ErrNotOwner) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Member invited successfully",
+	})
+}
+
+// MarkRead marks the messages of a channel as read
+// @Summary Mark channel read
+// @Description Mark the messages of a channel the current user is a member of as read, up to and including message_id or else up to read_at. The pointer never moves back to an older message.
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Channel ID"
+// @Param request body httpTransport.MarkReadRequest true "Last read message or time"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels/{id}/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Mark read
+	if err := h.service.MarkRead(c.Request.Context(), id, userID.(string), req.MessageID, req.ReadAt); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrNotMember) || errors.Is(err, channel.ErrMessageNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Marked as read successfully",
+	})
+}
+
+// Members lists the members of a channel
+// @Summary List channel members
+// @Description List the members of a public channel, or of a private channel the current user is a member of, in the order they joined
+// @Tags channels
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Channel ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.MemberListResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/channels/{id}/members [get]
+func (h *Handler) Members(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.ListMembersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get members
+	page, err := h.service.ListMembers(c.Request.Context(), id, userID.(string), req.Limit, req.Cursor)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, channel.ErrInvalidCursor) || errors.Is(err, channel.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, channel.ErrChannelNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.MemberListResponse{
+		Members:    make([]httpTransport.MemberResponse, len(page.Members)),
+		NextCursor: page.NextCursor,
+	}
+	for i, member := range page.Members {
+		response.Members[i] = httpTransport.MemberResponse{
+			UserID:   member.UserID,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// newChannelResponse maps a channel to its response representation
+func newChannelResponse(ch *channel.Channel) httpTransport.ChannelResponse {
+	return httpTransport.ChannelResponse{
+		ID:          ch.ID,
+		Name:        ch.Name,
+		Description: ch.Description,
+		Visibility:  ch.Visibility,
+		CreatedBy:   ch.CreatedBy,
+		MemberCount: ch.MemberCount,
+		CreatedAt:   ch.CreatedAt,
+		UpdatedAt:   ch.UpdatedAt,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,476 @@
+package conversation
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/core/auth"
+	"github.com/ivmello/go-api-template/internal/core/conversation"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+)
+
+// Handler handles conversation HTTP requests
+type Handler struct {
+	service *conversation.Service
+}
+
+// NewHandler creates a new conversation handler
+func NewHandler(service *conversation.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Create starts a conversation
+// @Summary Create conversation
+// @Description Start a private conversation between the current user and the participants. The kind defaults to direct for one other participant and group otherwise. Starting a direct conversation that already exists returns it with status 200.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body httpTransport.CreateConversationRequest true "Conversation data"
+// @Success 200 {object} httpTransport.ConversationResponse
+// @Success 201 {object} httpTransport.ConversationResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations [post]
+func (h *Handler) Create(c *gin.Context) {
+	var req httpTransport.CreateConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Create conversation
+	conv, created, err := h.service.Create(c.Request.Context(), userID.(string), req.ParticipantIDs, req.Kind, req.Title)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrNoParticipants) || errors.Is(err, conversation.ErrTooManyParticipants) ||
+			errors.Is(err, conversation.ErrInvalidKind) || errors.Is(err, conversation.ErrInvalidTitle) ||
+			errors.Is(err, conversation.ErrInvalidDirect) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, auth.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, newConversationResponse(conv))
+}
+
+// List retrieves a page of the current user's conversations
+// @Summary List conversations
+// @Description List the conversations the current user takes part in, most recently active first, with their unread counts
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.ConversationListResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations [get]
+func (h *Handler) List(c *gin.Context) {
+	var req httpTransport.ListConversationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get conversations
+	page, err := h.service.List(c.Request.Context(), userID.(string), req.Limit, req.Cursor)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrInvalidCursor) || errors.Is(err, conversation.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.ConversationListResponse{
+		Conversations: make([]httpTransport.ConversationResponse, len(page.Conversations)),
+		NextCursor:    page.NextCursor,
+	}
+	for i, conv := range page.Conversations {
+		response.Conversations[i] = newConversationResponse(conv)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get retrieves a single conversation by ID
+// @Summary Get conversation by ID
+// @Description Get a conversation the current user takes part in, with their unread count
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} httpTransport.ConversationResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get conversation
+	conv, err := h.service.Get(c.Request.Context(), id, userID.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrConversationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newConversationResponse(conv))
+}
+
+// AddParticipants adds users to a group conversation
+// @Summary Add participants
+// @Description Add users to a group conversation the current user takes part in. The participants of a direct conversation can't change.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Conversation ID"
+// @Param request body httpTransport.AddParticipantsRequest true "Users to add"
+// @Success 200 {object} httpTransport.ConversationResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations/{id}/participants [post]
+func (h *Handler) AddParticipants(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.AddParticipantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Add participants
+	conv, err := h.service.AddParticipants(c.Request.Context(), id, userID.(string), req.UserIDs)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrTooManyParticipants) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, conversation.ErrConversationNotFound) || errors.Is(err, auth.ErrUserNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, conversation.ErrDirectConversation) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newConversationResponse(conv))
+}
+
+// RemoveParticipant removes a user from a group conversation
+// @Summary Remove participant
+// @Description Remove a participant from a group conversation. Any participant can remove themselves to leave; only the creator can remove someone else.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Conversation ID"
+// @Param user_id path string true "User ID of the participant"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 409 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations/{id}/participants/{user_id} [delete]
+func (h *Handler) RemoveParticipant(c *gin.Context) {
+	id := c.Param("id")
+	participantID := c.Param("user_id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Remove participant
+	if err := h.service.RemoveParticipant(c.Request.Context(), id, userID.(string), participantID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrConversationNotFound) || errors.Is(err, conversation.ErrNotParticipant) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, conversation.ErrNotCreator) {
+			status = http.StatusForbidden
+		} else if errors.Is(err, conversation.ErrDirectConversation) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Participant removed successfully",
+	})
+}
+
+// SendMessage sends a message to a conversation
+// @Summary Send conversation message
+// @Description Send a message to a conversation the current user takes part in. The message counts as read by its sender.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Conversation ID"
+// @Param request body httpTransport.SendConversationMessageRequest true "Message data"
+// @Success 201 {object} httpTransport.ConversationMessageResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations/{id}/messages [post]
+func (h *Handler) SendMessage(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.SendConversationMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Send message
+	msg, err := h.service.Send(c.Request.Context(), id, userID.(string), req.Content)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrConversationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newMessageResponse(msg))
+}
+
+// Messages lists the messages of a conversation
+// @Summary List conversation messages
+// @Description List the messages of a conversation the current user takes part in, newest first
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Conversation ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.ConversationMessageListResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations/{id}/messages [get]
+func (h *Handler) Messages(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.ListConversationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get messages
+	page, err := h.service.ListMessages(c.Request.Context(), id, userID.(string), req.Limit, req.Cursor)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrInvalidCursor) || errors.Is(err, conversation.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, conversation.ErrConversationNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.ConversationMessageListResponse{
+		Messages:   make([]httpTransport.ConversationMessageResponse, len(page.Messages)),
+		NextCursor: page.NextCursor,
+	}
+	for i, msg := range page.Messages {
+		response.Messages[i] = newMessageResponse(msg)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkRead moves the current user's last-read pointer
+// @Summary Mark conversation read
+// @Description Mark the messages of a conversation as read by the current user, up to and including message_id or else up to read_at. The pointer never moves back to an older message.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Conversation ID"
+// @Param request body httpTransport.MarkReadRequest true "Last read message or time"
+// @Success 200 {object} httpTransport.ConversationResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/conversations/{id}/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Mark read
+	conv, err := h.service.MarkRead(c.Request.Context(), id, userID.(string), req.MessageID, req.ReadAt)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, conversation.ErrConversationNotFound) || errors.Is(err, conversation.ErrMessageNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newConversationResponse(conv))
+}
+
+// newConversationResponse maps a conversation to its response representation
+func newConversationResponse(conv *conversation.Conversation) httpTransport.ConversationResponse {
+	response := httpTransport.ConversationResponse{
+		ID:             conv.ID,
+		Kind:           conv.Kind,
+		Title:          conv.Title,
+		CreatedBy:      conv.CreatedBy,
+		Participants:   make([]httpTransport.ParticipantResponse, len(conv.Participants)),
+		UnreadCount:    conv.UnreadCount,
+		LastActivityAt: conv.LastActivityAt,
+		CreatedAt:      conv.CreatedAt,
+		UpdatedAt:      conv.UpdatedAt,
+	}
+	for i, participant := range conv.Participants {
+		response.Participants[i] = httpTransport.ParticipantResponse{
+			UserID:            participant.UserID,
+			LastReadMessageID: participant.LastReadMessageID,
+			JoinedAt:          participant.JoinedAt,
+		}
+	}
+	return response
+}
+
+// newMessageResponse maps a conversation message to its response
+// representation
+func newMessageResponse(msg *conversation.Message) httpTransport.ConversationMessageResponse {
+	return httpTransport.ConversationMessageResponse{
+		ID:             msg.ID,
+		ConversationID: msg.ConversationID,
+		UserID:         msg.UserID,
+		Content:        msg.Content,
+		CreatedAt:      msg.CreatedAt,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,370 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/ivmello/go-api-template/internal/core/message"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+	pkgAuth "github.com/ivmello/go-api-template/pkg/auth"
+)
+
+// SocketSubprotocol is the WebSocket subprotocol spoken by the gateway
+const SocketSubprotocol = "messages.v1"
+
+const (
+	// socketWriteWait is the time allowed to write a frame
+	socketWriteWait = 10 * time.Second
+
+	// socketPongWait is the time allowed between pongs from the client
+	socketPongWait = 60 * time.Second
+
+	// socketPingPeriod is how often pings are sent, shorter than the pong wait
+	socketPingPeriod = socketPongWait * 9 / 10
+
+	// socketMaxCommandSize bounds the size of a command frame in bytes
+	socketMaxCommandSize = 8 << 10
+
+	// socketSendBuffer is the number of frames queued for a connection
+	// before events are dropped and the connection with them
+	socketSendBuffer = 64
+)
+
+// Gateway serves the message WebSocket, which accepts create, update and
+// delete commands and pushes message events to subscribed connections.
+// Events from every instance reach it through the message event stream.
+type Gateway struct {
+	service              *message.Service
+	requireVerifiedEmail bool
+	upgrader             websocket.Upgrader
+}
+
+// NewGateway creates a new message WebSocket gateway. When
+// requireVerifiedEmail is set, only users with a verified email can create,
+// edit or delete messages.
+func NewGateway(service *message.Service, requireVerifiedEmail bool) *Gateway {
+	return &Gateway{
+		service:              service,
+		requireVerifiedEmail: requireVerifiedEmail,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{SocketSubprotocol},
+			// Connections authenticate with a bearer token rather than
+			// cookies, so any origin may connect
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Serve upgrades the request to a WebSocket and runs the connection
+// @Summary Message WebSocket
+// @Description Bidirectional message gateway. Send SocketCommand frames to subscribe to events from your channels (optionally resuming after last_event_id) and to create, update or delete messages; the server replies with SocketFrame frames echoing the command id, and pushes event frames to subscribed connections. Browsers that can't set the Authorization header pass the access token as a "bearer.{token}" subprotocol next to "messages.v1". Slow connections are closed with code 1013 and should resume from their last event ID.
+// @Tags messages
+// @Security Bearer
+// @Success 101 {object} httpTransport.SocketFrame
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/ws [get]
+func (g *Gateway) Serve(c *gin.Context) {
+	value, _ := c.Get("claims")
+	claims, ok := value.(*pkgAuth.Claims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Upgrade connection; the upgrader writes the error response itself
+	ws, err := g.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	conn := &socketConn{
+		gateway:   g,
+		ws:        ws,
+		claims:    claims,
+		send:      make(chan []byte, socketSendBuffer),
+		cancel:    cancel,
+		closeCode: websocket.CloseGoingAway,
+	}
+
+	// The connection can't outlive the token it was opened with
+	if claims.ExpiresAt != nil {
+		timer := time.AfterFunc(time.Until(claims.ExpiresAt.Time), func() {
+			conn.close(websocket.ClosePolicyViolation, "access token expired")
+		})
+		defer timer.Stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.writePump(ctx)
+	}()
+	conn.readPump(ctx)
+	<-done
+}
+
+// socketConn is a single WebSocket connection. Only writePump writes to the
+// socket and only readPump reads from it.
+type socketConn struct {
+	gateway *Gateway
+	ws      *websocket.Conn
+	claims  *pkgAuth.Claims
+
+	// send queues encoded frames for writePump. Replies wait for room, which
+	// stops reading commands; events never wait and drop the connection.
+	send chan []byte
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	closeCode   int
+	closeReason string
+	stopEvents  context.CancelFunc
+}
+
+// close ends the connection with the given close code, keeping the first
+// reason given
+func (c *socketConn) close(code int, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeReason == "" {
+		c.closeCode = code
+		c.closeReason = reason
+	}
+	c.cancel()
+}
+
+// readPump reads and handles commands until the connection fails or ends
+func (c *socketConn) readPump(ctx context.Context) {
+	defer c.close(websocket.CloseNormalClosure, "connection closed")
+
+	c.ws.SetReadLimit(socketMaxCommandSize)
+	c.ws.SetReadDeadline(time.Now().Add(socketPongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	// writePump closes the socket when the connection ends, which unblocks
+	// ReadMessage
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.ws.SetReadDeadline(time.Now().Add(socketPongWait))
+
+		var cmd httpTransport.SocketCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			c.reply(ctx, httpTransport.SocketFrame{Type: httpTransport.SocketError, Status: http.StatusBadRequest, Error: "Invalid command format"})
+			continue
+		}
+		c.reply(ctx, c.handle(ctx, &cmd))
+	}
+}
+
+// handle runs a command and returns its reply
+func (c *socketConn) handle(ctx context.Context, cmd *httpTransport.SocketCommand) httpTransport.SocketFrame {
+	// Validate command
+	if err := cmd.Validate(); err != nil {
+		return socketError(cmd.ID, http.StatusBadRequest, err)
+	}
+
+	// Check access
+	if cmd.Type == httpTransport.SocketCreate || cmd.Type == httpTransport.SocketUpdate || cmd.Type == httpTransport.SocketDelete {
+		if !pkgAuth.HasPermission(c.claims.Roles, pkgAuth.PermMessagesWrite) {
+			return socketError(cmd.ID, http.StatusForbidden, errors.New("missing permission "+string(pkgAuth.PermMessagesWrite)))
+		}
+		if c.gateway.requireVerifiedEmail && !c.claims.EmailVerified {
+			return socketError(cmd.ID, http.StatusForbidden, errors.New("email address is not verified"))
+		}
+	}
+
+	reply := httpTransport.SocketFrame{Type: httpTransport.SocketOK, ID: cmd.ID}
+	switch cmd.Type {
+	case httpTransport.SocketSubscribe:
+		if err := c.subscribe(ctx, cmd.LastEventID); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, message.ErrInvalidEventID) {
+				status = http.StatusBadRequest
+			} else if errors.Is(err, message.ErrEventsExpired) {
+				status = http.StatusGone
+			} else if errors.Is(err, message.ErrEventStreamClosed) {
+				status = http.StatusServiceUnavailable
+			}
+			return socketError(cmd.ID, status, err)
+		}
+
+	case httpTransport.SocketUnsubscribe:
+		c.unsubscribe()
+
+	case httpTransport.SocketCreate:
+		msg, err := c.gateway.service.Create(ctx, cmd.ChannelID, c.claims.UserID, cmd.Content, cmd.ParentID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, message.ErrParentNotFound) {
+				status = http.StatusNotFound
+			} else if errors.Is(err, message.ErrNotMember) {
+				status = http.StatusForbidden
+			}
+			return socketError(cmd.ID, status, err)
+		}
+		response := newMessageResponse(msg, nil)
+		reply.Message = &response
+
+	case httpTransport.SocketUpdate, httpTransport.SocketDelete:
+		var err error
+		if cmd.Type == httpTransport.SocketUpdate {
+			err = c.gateway.service.Update(ctx, cmd.MessageID, c.claims.UserID, c.claims.Roles, cmd.Content)
+		} else {
+			err = c.gateway.service.Delete(ctx, cmd.MessageID, c.claims.UserID, c.claims.Roles)
+		}
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, message.ErrMessageNotFound) {
+				status = http.StatusNotFound
+			} else if errors.Is(err, message.ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			return socketError(cmd.ID, status, err)
+		}
+	}
+
+	return reply
+}
+
+// subscribe starts pushing events to the connection, replacing any previous
+// subscription
+func (c *socketConn) subscribe(ctx context.Context, lastEventID string) error {
+	c.unsubscribe()
+
+	subCtx, stop := context.WithCancel(ctx)
+	sub, err := c.gateway.service.Watch(subCtx, c.claims.UserID, lastEventID)
+	if err != nil {
+		stop()
+		return err
+	}
+
+	c.mu.Lock()
+	c.stopEvents = stop
+	c.mu.Unlock()
+
+	go c.forwardEvents(subCtx, sub)
+	return nil
+}
+
+// unsubscribe stops pushing events to the connection
+func (c *socketConn) unsubscribe() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopEvents != nil {
+		c.stopEvents()
+		c.stopEvents = nil
+	}
+}
+
+// forwardEvents queues events for the connection until the subscription
+// ends. A connection that can't keep up is closed so it can resume from its
+// last event instead of holding events for everyone else.
+func (c *socketConn) forwardEvents(ctx context.Context, sub *message.Subscription) {
+	for event := range sub.Events() {
+		response := httpTransport.MessageEventResponse{
+			Type:       string(event.Type),
+			Message:    newMessageResponse(event.Message, nil),
+			OccurredAt: event.OccurredAt,
+		}
+		data, err := json.Marshal(httpTransport.SocketFrame{
+			Type:    httpTransport.SocketEvent,
+			EventID: event.ID,
+			Event:   &response,
+		})
+		if err != nil {
+			continue
+		}
+
+		select {
+		case c.send <- data:
+		default:
+			c.close(websocket.CloseTryAgainLater, "slow consumer, resume from the last event ID")
+			return
+		}
+	}
+
+	// Ended by unsubscribe or by the connection closing
+	if ctx.Err() != nil {
+		return
+	}
+	if errors.Is(sub.Err(), message.ErrSubscriberLagged) {
+		c.close(websocket.CloseTryAgainLater, "slow consumer, resume from the last event ID")
+		return
+	}
+	c.close(websocket.CloseGoingAway, "event stream closed")
+}
+
+// reply queues a reply frame, waiting for room in the send buffer
+func (c *socketConn) reply(ctx context.Context, frame httpTransport.SocketFrame) {
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.send <- data:
+	case <-ctx.Done():
+	}
+}
+
+// writePump writes queued frames and pings until the connection ends, then
+// sends a close frame
+func (c *socketConn) writePump(ctx context.Context) {
+	ticker := time.NewTicker(socketPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.ws.Close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "write failed")
+				return
+			}
+
+		case <-ticker.C:
+			c.ws.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "ping failed")
+				return
+			}
+
+		case <-ctx.Done():
+			c.mu.Lock()
+			code, reason := c.closeCode, c.closeReason
+			c.mu.Unlock()
+			if reason == "" {
+				reason = "server shutting down"
+			}
+			c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(socketWriteWait))
+			return
+		}
+	}
+}
+
+// socketError builds an error reply
+func socketError(id string, status int, err error) httpTransport.SocketFrame {
+	return httpTransport.SocketFrame{
+		Type:   httpTransport.SocketError,
+		ID:     id,
+		Status: status,
+		Error:  err.Error(),
+	}
+}
\ No newline at end of file
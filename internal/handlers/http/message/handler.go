@@ -1,104 +1,420 @@
 package message
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ivmello/go-api-template/internal/core/message"
+	"github.com/ivmello/go-api-template/internal/core/reaction"
 	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
 )
 
+const (
+	// eventsHeartbeatInterval is how often an idle event stream sends a
+	// comment so proxies don't close the connection
+	eventsHeartbeatInterval = 15 * time.Second
+
+	// eventsRetry is the reconnection delay suggested to event stream clients
+	eventsRetry = 3 * time.Second
+)
+
 // Handler handles message HTTP requests
 type Handler struct {
-	service *message.Service
+	service   *message.Service
+	reactions *reaction.Service
 }
 
 // NewHandler creates a new message handler
-func NewHandler(service *message.Service) *Handler {
+func NewHandler(service *message.Service, reactions *reaction.Service) *Handler {
 	return &Handler{
-		service: service,
+		service:   service,
+		reactions: reactions,
 	}
 }
 
-// GetAll retrieves all messages
-// @Summary Get all messages
-// @Description Get a list of all messages
+// List retrieves a page of messages from a channel
+// @Summary List messages
+// @Description List the messages of a channel the current user is a member of, with cursor pagination, newest first by default. Pass next_cursor from a response as cursor to get the following page.
 // @Tags messages
 // @Accept json
 // @Produce json
-// @Success 200 {array} httpTransport.MessageResponse
+// @Security Bearer
+// @Security ApiKey
+// @Param channel_id query string true "Channel to list messages from"
+// @Param user_id query string false "Only messages by this user"
+// @Param created_after query string false "Only messages created at or after this RFC 3339 time"
+// @Param created_before query string false "Only messages created before this RFC 3339 time"
+// @Param order query string false "Sort order by creation time" Enums(asc, desc)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.MessageListResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
 // @Failure 500 {object} httpTransport.ErrorResponse
 // @Router /api/v1/messages [get]
-func (h *Handler) GetAll(c *gin.Context) {
-	messages, err := h.service.GetAll(c.Request.Context())
+func (h *Handler) List(c *gin.Context) {
+	var req httpTransport.ListMessagesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get messages
+	page, err := h.service.List(c.Request.Context(), userID.(string), message.ListOptions{
+		ChannelID:     req.ChannelID,
+		UserID:        req.UserID,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Order:         req.Order,
+		Limit:         req.Limit,
+		Cursor:        req.Cursor,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrInvalidCursor) || errors.Is(err, message.ErrInvalidSortOrder) || errors.Is(err, message.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get reactions
+	summaries, err := h.reactionSummaries(c, page.Messages)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// Map domain objects to response objects
-	response := make([]httpTransport.MessageResponse, len(messages))
-	for i, msg := range messages {
-		response[i] = httpTransport.MessageResponse{
-			ID:        msg.ID,
-			UserID:    msg.UserID,
-			Content:   msg.Content,
-			CreatedAt: msg.CreatedAt,
-			UpdatedAt: msg.UpdatedAt,
+	response := httpTransport.MessageListResponse{
+		Messages:   make([]httpTransport.MessageResponse, len(page.Messages)),
+		NextCursor: page.NextCursor,
+	}
+	for i, msg := range page.Messages {
+		response.Messages[i] = newMessageResponse(msg, summaries[msg.ID])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Search runs a full-text search over messages
+// @Summary Search messages
+// @Description Ranked full-text search over the content of messages in the current user's channels, best match first. Supports web search syntax: "quoted phrases", OR and -exclusion. Snippets are plain message text with matches wrapped in <mark> tags, so escape them before rendering as HTML.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param q query string true "Search query"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.MessageSearchResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/search [get]
+func (h *Handler) Search(c *gin.Context) {
+	var req httpTransport.SearchMessagesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Search messages
+	page, err := h.service.Search(c.Request.Context(), userID.(string), req.Query, req.Limit, req.Cursor)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrInvalidCursor) || errors.Is(err, message.ErrInvalidSearchQuery) || errors.Is(err, message.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get reactions
+	messages := make([]*message.Message, len(page.Results))
+	for i, result := range page.Results {
+		messages[i] = result.Message
+	}
+	summaries, err := h.reactionSummaries(c, messages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.MessageSearchResponse{
+		Results:    make([]httpTransport.MessageSearchResult, len(page.Results)),
+		NextCursor: page.NextCursor,
+	}
+	for i, result := range page.Results {
+		response.Results[i] = httpTransport.MessageSearchResult{
+			Message: newMessageResponse(result.Message, summaries[result.Message.ID]),
+			Rank:    result.Rank,
+			Snippet: result.Snippet,
 		}
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// Events streams message events as Server-Sent Events
+// @Summary Stream message events
+// @Description Stream lifecycle events of messages in the current user's channels as text/event-stream. Each event's data is a MessageEventResponse and its id can be sent back in the Last-Event-ID header (or the last_event_id query parameter) to resume without missing events. Idle streams receive heartbeat comments. A 410 means the events to resume from are no longer retained and the client should reload messages before reconnecting without an ID.
+// @Tags messages
+// @Produce text/event-stream
+// @Security Bearer
+// @Security ApiKey
+// @Param Last-Event-ID header string false "Resume after this event"
+// @Param last_event_id query string false "Resume after this event, for clients that can't set headers"
+// @Success 200 {object} httpTransport.MessageEventResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 410 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Failure 503 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/events [get]
+func (h *Handler) Events(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Subscribe to events
+	sub, err := h.service.Watch(c.Request.Context(), userID.(string), lastEventID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrInvalidEventID) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, message.ErrEventsExpired) {
+			status = http.StatusGone
+		} else if errors.Is(err, message.ErrEventStreamClosed) {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", eventsRetry.Milliseconds())
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Write events until the client goes away or the subscription ends, in
+	// which case the client reconnects with its last event ID
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(httpTransport.MessageEventResponse{
+				Type:       string(event.Type),
+				Message:    newMessageResponse(event.Message, nil),
+				OccurredAt: event.OccurredAt,
+			})
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %s\ndata: %s\n\n", event.ID, data)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
 // Get retrieves a single message by ID
 // @Summary Get message by ID
-// @Description Get a message by its ID
+// @Description Get a message by its ID from a channel the current user is a member of
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Security ApiKey
 // @Param id path string true "Message ID"
 // @Success 200 {object} httpTransport.MessageResponse
 // @Failure 400 {object} httpTransport.ErrorResponse
 // @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
 // @Failure 404 {object} httpTransport.ErrorResponse
 // @Failure 500 {object} httpTransport.ErrorResponse
 // @Router /api/v1/messages/{id} [get]
 func (h *Handler) Get(c *gin.Context) {
 	id := c.Param("id")
 
-	msg, err := h.service.GetByID(c.Request.Context(), id)
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get message
+	msg, err := h.service.Get(c.Request.Context(), id, userID.(string))
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, message.ErrMessageNotFound) {
 			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
 		}
 		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, httpTransport.MessageResponse{
-		ID:        msg.ID,
-		UserID:    msg.UserID,
-		Content:   msg.Content,
-		CreatedAt: msg.CreatedAt,
-		UpdatedAt: msg.UpdatedAt,
-	})
+	// Get reactions
+	summaries, err := h.reactionSummaries(c, []*message.Message{msg})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newMessageResponse(msg, summaries[msg.ID]))
+}
+
+// Thread retrieves a message and its replies
+// @Summary Get message thread
+// @Description Get a message and its replies at any depth, either as a tree or as a flat list in reading order with each message's depth. Deleted messages that still have replies are returned as tombstones without content.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Message ID"
+// @Param format query string false "Response shape (default tree)" Enums(tree, flat)
+// @Success 200 {object} httpTransport.ThreadResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/{id}/thread [get]
+func (h *Handler) Thread(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.GetThreadRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get thread
+	thread, err := h.service.GetThread(c.Request.Context(), id, userID.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrMessageNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get reactions
+	nodes := thread.Flatten()
+	messages := make([]*message.Message, len(nodes))
+	for i, node := range nodes {
+		messages[i] = node.Message
+	}
+	summaries, err := h.reactionSummaries(c, messages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.ThreadResponse{Truncated: thread.Truncated}
+	if req.Format == "flat" {
+		response.Messages = make([]httpTransport.ThreadNodeResponse, len(nodes))
+		for i, node := range nodes {
+			response.Messages[i] = httpTransport.ThreadNodeResponse{
+				Message: newMessageResponse(node.Message, summaries[node.Message.ID]),
+				Depth:   node.Depth,
+			}
+		}
+	} else {
+		root := newThreadNodeResponse(thread.Root, summaries)
+		response.Root = &root
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // Create creates a new message
 // @Summary Create message
-// @Description Create a new message
+// @Description Create a new message in a channel the current user is a member of, or a reply to another message of the channel when parent_id is set
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Security ApiKey
 // @Param request body httpTransport.CreateMessageRequest true "Message content"
 // @Success 201 {object} httpTransport.MessageResponse
 // @Failure 400 {object} httpTransport.ErrorResponse
 // @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
 // @Failure 500 {object} httpTransport.ErrorResponse
 // @Router /api/v1/messages [post]
 func (h *Handler) Create(c *gin.Context) {
@@ -122,28 +438,29 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 
 	// Create message
-	msg, err := h.service.Create(c.Request.Context(), userID.(string), req.Content)
+	msg, err := h.service.Create(c.Request.Context(), req.ChannelID, userID.(string), req.Content, req.ParentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrParentNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, httpTransport.MessageResponse{
-		ID:        msg.ID,
-		UserID:    msg.UserID,
-		Content:   msg.Content,
-		CreatedAt: msg.CreatedAt,
-		UpdatedAt: msg.UpdatedAt,
-	})
+	c.JSON(http.StatusCreated, newMessageResponse(msg, nil))
 }
 
 // Update updates a message
 // @Summary Update message
-// @Description Update an existing message
+// @Description Update an existing message. Moderators and admins may update any message.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Security ApiKey
 // @Param id path string true "Message ID"
 // @Param request body httpTransport.UpdateMessageRequest true "Updated message content"
 // @Success 200 {object} httpTransport.SuccessResponse
@@ -176,7 +493,7 @@ func (h *Handler) Update(c *gin.Context) {
 	}
 
 	// Update message
-	err := h.service.Update(c.Request.Context(), id, userID.(string), req.Content)
+	err := h.service.Update(c.Request.Context(), id, userID.(string), c.GetStringSlice("roles"), req.Content)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, message.ErrMessageNotFound) {
@@ -195,11 +512,12 @@ func (h *Handler) Update(c *gin.Context) {
 
 // Delete deletes a message
 // @Summary Delete message
-// @Description Delete an existing message
+// @Description Move an existing message to the trash, where it can be restored until it is purged. Moderators and admins may delete any message.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Security ApiKey
 // @Param id path string true "Message ID"
 // @Success 200 {object} httpTransport.SuccessResponse
 // @Failure 401 {object} httpTransport.ErrorResponse
@@ -218,7 +536,7 @@ func (h *Handler) Delete(c *gin.Context) {
 	}
 
 	// Delete message
-	err := h.service.Delete(c.Request.Context(), id, userID.(string))
+	err := h.service.Delete(c.Request.Context(), id, userID.(string), c.GetStringSlice("roles"))
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, message.ErrMessageNotFound) {
@@ -233,4 +551,382 @@ func (h *Handler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
 		Message: "Message deleted successfully",
 	})
+}
+
+// Trash lists the current user's deleted messages
+// @Summary List trash
+// @Description List the current user's deleted messages, most recently deleted first. Messages are purged permanently after the retention period.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from a previous page"
+// @Success 200 {object} httpTransport.MessageListResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/trash [get]
+func (h *Handler) Trash(c *gin.Context) {
+	var req httpTransport.ListTrashRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get deleted messages
+	page, err := h.service.ListTrash(c.Request.Context(), userID.(string), req.Limit, req.Cursor)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrInvalidCursor) || errors.Is(err, message.ErrInvalidPageSize) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get reactions
+	summaries, err := h.reactionSummaries(c, page.Messages)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.MessageListResponse{
+		Messages:   make([]httpTransport.MessageResponse, len(page.Messages)),
+		NextCursor: page.NextCursor,
+	}
+	for i, msg := range page.Messages {
+		response.Messages[i] = newMessageResponse(msg, summaries[msg.ID])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Restore restores a deleted message
+// @Summary Restore message
+// @Description Take a message out of the trash. Moderators and admins may restore any message.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Message ID"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/{id}/restore [post]
+func (h *Handler) Restore(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Restore message
+	err := h.service.Restore(c.Request.Context(), id, userID.(string), c.GetStringSlice("roles"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrMessageNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Message restored successfully",
+	})
+}
+
+// AddReaction reacts to a message
+// @Summary Add reaction
+// @Description React to a message with an emoji. Each user can add a given emoji once per message; adding it again has no effect.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Message ID"
+// @Param emoji path string true "Emoji, URL encoded"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/{id}/reactions/{emoji} [put]
+func (h *Handler) AddReaction(c *gin.Context) {
+	id := c.Param("id")
+	emoji := c.Param("emoji")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Add reaction
+	if err := h.reactions.Add(c.Request.Context(), id, userID.(string), emoji); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrMessageNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
+		} else if errors.Is(err, reaction.ErrInvalidEmoji) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Reaction added successfully",
+	})
+}
+
+// RemoveReaction takes back a reaction to a message
+// @Summary Remove reaction
+// @Description Remove the current user's emoji reaction from a message
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Message ID"
+// @Param emoji path string true "Emoji, URL encoded"
+// @Success 200 {object} httpTransport.SuccessResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/{id}/reactions/{emoji} [delete]
+func (h *Handler) RemoveReaction(c *gin.Context) {
+	id := c.Param("id")
+	emoji := c.Param("emoji")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Remove reaction
+	if err := h.reactions.Remove(c.Request.Context(), id, userID.(string), emoji); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, reaction.ErrReactionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, httpTransport.SuccessResponse{
+		Message: "Reaction removed successfully",
+	})
+}
+
+// Revisions lists the edit history of a message
+// @Summary List message revisions
+// @Description List every revision of a message, oldest first. Revision 1 is the original content.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Message ID"
+// @Success 200 {array} httpTransport.RevisionResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/{id}/revisions [get]
+func (h *Handler) Revisions(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get revisions
+	revisions, err := h.service.ListRevisions(c.Request.Context(), id, userID.(string))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrMessageNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := make([]httpTransport.RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		response[i] = newRevisionResponse(revision)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DiffRevisions compares two revisions of a message
+// @Summary Diff message revisions
+// @Description Get the line-level difference between two revisions of a message
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "Message ID"
+// @Param from query int true "Revision to compare from"
+// @Param to query int true "Revision to compare to"
+// @Success 200 {object} httpTransport.RevisionDiffResponse
+// @Failure 400 {object} httpTransport.ErrorResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 403 {object} httpTransport.ErrorResponse
+// @Failure 404 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/messages/{id}/revisions/diff [get]
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	id := c.Param("id")
+
+	var req httpTransport.DiffRevisionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: "Invalid query parameters"})
+		return
+	}
+
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Diff revisions
+	result, err := h.service.DiffRevisions(c.Request.Context(), id, userID.(string), req.From, req.To)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, message.ErrMessageNotFound) || errors.Is(err, message.ErrRevisionNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, message.ErrInvalidRevision) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, message.ErrNotMember) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.RevisionDiffResponse{
+		From:  newRevisionResponse(result.From),
+		To:    newRevisionResponse(result.To),
+		Lines: make([]httpTransport.DiffLineResponse, len(result.Lines)),
+	}
+	for i, line := range result.Lines {
+		response.Lines[i] = httpTransport.DiffLineResponse{
+			Op:   string(line.Op),
+			Text: line.Text,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// reactionSummaries loads the reactions to the messages, marking those of
+// the current user
+func (h *Handler) reactionSummaries(c *gin.Context, messages []*message.Message) (map[string]*reaction.Summary, error) {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+	return h.reactions.Summaries(c.Request.Context(), ids, c.GetString("user_id"))
+}
+
+// newMessageResponse maps a message and its reactions, which may be nil, to
+// its response representation
+func newMessageResponse(msg *message.Message, reactions *reaction.Summary) httpTransport.MessageResponse {
+	response := httpTransport.MessageResponse{
+		ID:            msg.ID,
+		ChannelID:     msg.ChannelID,
+		UserID:        msg.UserID,
+		ParentID:      msg.ParentID,
+		Content:       msg.Content,
+		Edited:        msg.IsEdited(),
+		RevisionCount: msg.RevisionCount,
+		ReplyCount:    msg.ReplyCount,
+		CreatedAt:     msg.CreatedAt,
+		UpdatedAt:     msg.UpdatedAt,
+		DeletedAt:     msg.DeletedAt,
+		Reactions:     []httpTransport.ReactionCountResponse{},
+		MyReactions:   []string{},
+	}
+	if reactions != nil {
+		for _, count := range reactions.Counts {
+			response.Reactions = append(response.Reactions, httpTransport.ReactionCountResponse{
+				Emoji: count.Emoji,
+				Count: count.Count,
+			})
+		}
+		response.MyReactions = append(response.MyReactions, reactions.Mine...)
+	}
+	return response
+}
+
+// newThreadNodeResponse maps a thread node and its replies to their response
+// representation
+func newThreadNodeResponse(node *message.ThreadNode, summaries map[string]*reaction.Summary) httpTransport.ThreadNodeResponse {
+	response := httpTransport.ThreadNodeResponse{
+		Message: newMessageResponse(node.Message, summaries[node.Message.ID]),
+		Depth:   node.Depth,
+		Replies: make([]httpTransport.ThreadNodeResponse, len(node.Replies)),
+	}
+	for i, reply := range node.Replies {
+		response.Replies[i] = newThreadNodeResponse(reply, summaries)
+	}
+	return response
+}
+
+// newRevisionResponse maps a revision to its response representation
+func newRevisionResponse(revision *message.Revision) httpTransport.RevisionResponse {
+	return httpTransport.RevisionResponse{
+		Revision:  revision.Revision,
+		Content:   revision.Content,
+		EditedBy:  revision.EditedBy,
+		CreatedAt: revision.CreatedAt,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package unread
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/core/unread"
+	httpTransport "github.com/ivmello/go-api-template/internal/transport/http"
+)
+
+// Handler handles unread message HTTP requests
+type Handler struct {
+	service *unread.Service
+}
+
+// NewHandler creates a new unread handler
+func NewHandler(service *unread.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Get retrieves the current user's unread message counts
+// @Summary Get unread counts
+// @Description Get the number of unread messages of the current user in total and per channel and conversation. Channels and conversations without unread messages are left out.
+// @Tags unread
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} httpTransport.UnreadResponse
+// @Failure 401 {object} httpTransport.ErrorResponse
+// @Failure 500 {object} httpTransport.ErrorResponse
+// @Router /api/v1/unread [get]
+func (h *Handler) Get(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, httpTransport.ErrorResponse{Error: "Not authenticated"})
+		return
+	}
+
+	// Get unread counts
+	summary, err := h.service.Summary(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, httpTransport.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Map domain objects to response objects
+	response := httpTransport.UnreadResponse{
+		Total:         summary.Total,
+		Channels:      []httpTransport.UnreadCountResponse{},
+		Conversations: []httpTransport.UnreadCountResponse{},
+	}
+	for _, count := range summary.Counts {
+		item := httpTransport.UnreadCountResponse{ID: count.Target.ID, Unread: count.Unread}
+		if count.Target.Kind == unread.KindChannel {
+			response.Channels = append(response.Channels, item)
+		} else {
+			response.Conversations = append(response.Conversations, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
\ No newline at end of file
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrMiss = errors.New("cache miss")
+
+// Cache is a typed cache. Entries expire after their TTL, or never for a TTL
+// of zero, and can be tagged so related entries are invalidated together.
+type Cache[T any] interface {
+	// Get returns the value under key, or ErrMiss
+	Get(ctx context.Context, key string) (T, error)
+
+	// GetMany returns the values under the keys that are cached
+	GetMany(ctx context.Context, keys []string) (map[string]T, error)
+
+	// Set stores a value under key with the given tags
+	Set(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error
+
+	// SetMany stores several values that share a TTL and tags
+	SetMany(ctx context.Context, values map[string]T, ttl time.Duration, tags ...string) error
+
+	// GetOrLoad returns the value under key, loading and storing it on a
+	// miss. Concurrent misses for the same key share one load, and cache
+	// failures fall back to the loaded value rather than failing the call.
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error), tags ...string) (T, error)
+
+	// Delete removes the values under the keys
+	Delete(ctx context.Context, keys ...string) error
+
+	// Invalidate removes every value stored with any of the tags
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// Entry is an encoded value stored under a key
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// Store holds encoded cache entries
+type Store interface {
+	// Get returns the value under each key, or nil for keys that aren't
+	// cached
+	Get(ctx context.Context, keys []string) ([][]byte, error)
+	Set(ctx context.Context, entries []Entry, ttl time.Duration, tags []string) error
+	Delete(ctx context.Context, keys []string) error
+	Invalidate(ctx context.Context, tags []string) error
+}
+
+// typedCache implements Cache on top of a store and a codec
+type typedCache[T any] struct {
+	store Store
+	codec Codec
+	group singleflight.Group
+}
+
+// New creates a typed cache over a store, encoding values with the codec
+func New[T any](store Store, codec Codec) Cache[T] {
+	return &typedCache[T]{
+		store: store,
+		codec: codec,
+	}
+}
+
+// NewRedis creates a typed cache in Redis. Keys are prefixed with prefix.
+func NewRedis[T any](client *redis.Client, prefix string, codec Codec) Cache[T] {
+	return New[T](NewRedisStore(client, prefix), codec)
+}
+
+// NewLRU creates a typed cache in process memory holding up to size entries
+func NewLRU[T any](size int, codec Codec) Cache[T] {
+	return New[T](NewLRUStore(size), codec)
+}
+
+// NewTwoTier creates a typed cache that keeps up to size entries in process
+// memory for at most localTTL in front of Redis
+func NewTwoTier[T any](client *redis.Client, prefix string, size int, localTTL time.Duration, codec Codec) Cache[T] {
+	return New[T](NewTwoTierStore(NewLRUStore(size), NewRedisStore(client, prefix), localTTL), codec)
+}
+
+func (c *typedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+	data, err := c.store.Get(ctx, []string{key})
+	if err != nil {
+		return value, err
+	}
+	if data[0] == nil {
+		return value, ErrMiss
+	}
+
+	err = c.codec.Unmarshal(data[0], &value)
+	return value, err
+}
+
+func (c *typedCache[T]) GetMany(ctx context.Context, keys []string) (map[string]T, error) {
+	data, err := c.store.Get(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]T, len(keys))
+	for i, key := range keys {
+		if data[i] == nil {
+			continue
+		}
+		var value T
+		if err := c.codec.Unmarshal(data[i], &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+func (c *typedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Set(ctx, []Entry{{Key: key, Value: data}}, ttl, tags)
+}
+
+func (c *typedCache[T]) SetMany(ctx context.Context, values map[string]T, ttl time.Duration, tags ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	entries := make([]Entry, 0, len(values))
+	for key, value := range values {
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, Entry{Key: key, Value: data})
+	}
+
+	return c.store.Set(ctx, entries, ttl, tags)
+}
+
+func (c *typedCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	var value T
+	data, err := c.store.Get(ctx, []string{key})
+	if err == nil && data[0] != nil {
+		if err := c.codec.Unmarshal(data[0], &value); err == nil {
+			return value, nil
+		}
+	}
+
+	// The shared load outlives the caller that started it, so one caller
+	// giving up doesn't fail the others. Each caller decodes its own copy,
+	// so callers never share a value.
+	shared, err, _ := c.group.Do(key, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		// A failed store only costs the next caller another load
+		_ = c.store.Set(ctx, []Entry{{Key: key, Value: data}}, ttl, tags)
+		return data, nil
+	})
+	if err != nil {
+		return value, err
+	}
+
+	err = c.codec.Unmarshal(shared.([]byte), &value)
+	return value, err
+}
+
+func (c *typedCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.store.Delete(ctx, keys)
+}
+
+func (c *typedCache[T]) Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return c.store.Invalidate(ctx, tags)
+}
\ No newline at end of file
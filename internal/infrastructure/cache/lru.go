@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// lruEntry is an entry of an LRU store
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // Zero for entries that don't expire
+	tags      []string
+}
+
+// LRUStore keeps up to a fixed number of cache entries in process memory,
+// evicting the least recently used entry when full. Expired entries are
+// dropped when they are read or evicted.
+type LRUStore struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+}
+
+// NewLRUStore creates a new LRU store holding up to size entries
+func NewLRUStore(size int) *LRUStore {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUStore{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		tags:    make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns the value under each key, or nil for keys that aren't cached
+func (s *LRUStore) Get(ctx context.Context, keys []string) ([][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	data := make([][]byte, len(keys))
+	for i, key := range keys {
+		element, ok := s.entries[key]
+		if !ok {
+			continue
+		}
+		entry := element.Value.(*lruEntry)
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			s.remove(element)
+			continue
+		}
+		s.order.MoveToFront(element)
+		data[i] = entry.value
+	}
+
+	return data, nil
+}
+
+// Set stores the entries with the given TTL and tags
+func (s *LRUStore) Set(ctx context.Context, entries []Entry, ttl time.Duration, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	for _, e := range entries {
+		if element, ok := s.entries[e.Key]; ok {
+			s.remove(element)
+		}
+
+		entry := &lruEntry{
+			key:       e.Key,
+			value:     e.Value,
+			expiresAt: expiresAt,
+			tags:      tags,
+		}
+		s.entries[e.Key] = s.order.PushFront(entry)
+		for _, tag := range tags {
+			if s.tags[tag] == nil {
+				s.tags[tag] = make(map[string]struct{})
+			}
+			s.tags[tag][e.Key] = struct{}{}
+		}
+
+		if s.order.Len() > s.size {
+			s.remove(s.order.Back())
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the entries under the keys
+func (s *LRUStore) Delete(ctx context.Context, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := s.entries[key]; ok {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// Invalidate removes every entry stored with any of the tags
+func (s *LRUStore) Invalidate(ctx context.Context, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			if element, ok := s.entries[key]; ok {
+				s.remove(element)
+			}
+		}
+		delete(s.tags, tag)
+	}
+
+	return nil
+}
+
+// remove drops an entry and its tag memberships. The caller holds the lock.
+func (s *LRUStore) remove(element *list.Element) {
+	entry := s.order.Remove(element).(*lruEntry)
+	delete(s.entries, entry.key)
+
+	for _, tag := range entry.tags {
+		delete(s.tags[tag], entry.key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// entries returns entries with the keys, each holding its key as value
+func entries(keys ...string) []Entry {
+	e := make([]Entry, len(keys))
+	for i, key := range keys {
+		e[i] = Entry{Key: key, Value: []byte(key)}
+	}
+	return e
+}
+
+// cached returns the keys a store holds out of the given ones
+func cached(t *testing.T, store Store, keys ...string) []string {
+	t.Helper()
+
+	data, err := store.Get(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	var found []string
+	for i, value := range data {
+		if value != nil {
+			found = append(found, keys[i])
+		}
+	}
+	return found
+}
+
+func TestLRUStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewLRUStore(2)
+	ctx := context.Background()
+
+	store.Set(ctx, entries("a", "b"), 0, nil)
+
+	// Reading a makes b the least recently used entry
+	cached(t, store, "a")
+	store.Set(ctx, entries("c"), 0, nil)
+
+	if got := cached(t, store, "a", "b", "c"); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Fatalf("cached keys = %v, want [a c]", got)
+	}
+	if len(store.tags) != 0 {
+		t.Errorf("tags = %v, want none", store.tags)
+	}
+}
+
+func TestLRUStoreExpiresEntries(t *testing.T) {
+	store := NewLRUStore(10)
+	ctx := context.Background()
+
+	store.Set(ctx, entries("short"), time.Millisecond, nil)
+	store.Set(ctx, entries("forever"), 0, nil)
+	time.Sleep(5 * time.Millisecond)
+
+	if got := cached(t, store, "short", "forever"); len(got) != 1 || got[0] != "forever" {
+		t.Fatalf("cached keys = %v, want [forever]", got)
+	}
+	if store.order.Len() != 1 {
+		t.Errorf("store holds %d entries, want 1", store.order.Len())
+	}
+}
+
+func TestLRUStoreInvalidate(t *testing.T) {
+	store := NewLRUStore(10)
+	ctx := context.Background()
+
+	store.Set(ctx, entries("a", "b"), 0, []string{"red"})
+	store.Set(ctx, entries("c"), 0, []string{"red", "blue"})
+	store.Set(ctx, entries("d"), 0, []string{"blue"})
+
+	store.Invalidate(ctx, []string{"red"})
+
+	if got := cached(t, store, "a", "b", "c", "d"); len(got) != 1 || got[0] != "d" {
+		t.Fatalf("cached keys = %v, want [d]", got)
+	}
+	if _, ok := store.tags["red"]; ok {
+		t.Error("the invalidated tag is still tracked")
+	}
+	if keys := store.tags["blue"]; len(keys) != 1 {
+		t.Errorf("blue tags %v, want only d", keys)
+	}
+}
+
+func TestLRUStoreTagsFollowRemovedEntries(t *testing.T) {
+	store := NewLRUStore(1)
+	ctx := context.Background()
+
+	// Overwriting an entry replaces its tags
+	store.Set(ctx, entries("a"), 0, []string{"red"})
+	store.Set(ctx, entries("a"), 0, []string{"blue"})
+	if _, ok := store.tags["red"]; ok {
+		t.Error("an overwritten entry is still tracked under its old tag")
+	}
+
+	// Evicting an entry drops its tags
+	store.Set(ctx, entries("b"), 0, nil)
+	if len(store.tags) != 0 {
+		t.Errorf("tags = %v after eviction, want none", store.tags)
+	}
+
+	// Deleting an entry drops its tags
+	store.Set(ctx, entries("c"), 0, []string{"green"})
+	store.Delete(ctx, []string{"c"})
+	if len(store.tags) != 0 {
+		t.Errorf("tags = %v after delete, want none", store.tags)
+	}
+}
\ No newline at end of file
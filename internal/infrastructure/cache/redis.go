@@ -2,8 +2,11 @@ package cache
 
 import (
 	"context"
-	"github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+
 	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/redis/go-redis/v9"
 )
 
 // NewRedisClient creates a new Redis client
@@ -23,17 +26,151 @@ func NewRedisClient(ctx context.Context, cfg *config.Config) (*redis.Client, err
 	return client, nil
 }
 
-// Set stores a value in Redis with expiration
-func Set(ctx context.Context, client *redis.Client, key string, value interface{}, expiration int) error {
-	return client.Set(ctx, key, value, 0).Err()
+const (
+	// tagKeyInfix separates the keys of tag sets, which hold the keys stored
+	// with a tag, from the keys of entries
+	tagKeyInfix = "#tag:"
+
+	// deleteBatchSize is the number of keys deleted per DEL call in scripts
+	deleteBatchSize = 1000
+)
+
+// setScript stores entries and adds their keys to the sets of their tags.
+// KEYS holds ARGV[1] entry keys followed by the tag sets; ARGV[2] is the TTL
+// in milliseconds, zero for none, followed by the entry values. Tag sets live
+// as long as the longest-lived entry added to them.
+var setScript = redis.NewScript(`
+local n = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+for i = 1, n do
+	if ttl > 0 then
+		redis.call("SET", KEYS[i], ARGV[i + 2], "PX", ttl)
+	else
+		redis.call("SET", KEYS[i], ARGV[i + 2])
+	end
+end
+for i = n + 1, #KEYS do
+	local remaining = redis.call("PTTL", KEYS[i])
+	for j = 1, n do
+		redis.call("SADD", KEYS[i], KEYS[j])
+	end
+	if ttl == 0 then
+		redis.call("PERSIST", KEYS[i])
+	elseif remaining == -2 or (remaining >= 0 and remaining < ttl) then
+		redis.call("PEXPIRE", KEYS[i], ttl)
+	end
+end
+return 0
+`)
+
+// invalidateScript deletes the entries in every tag set in KEYS and the sets
+var invalidateScript = redis.NewScript(`
+for _, tag in ipairs(KEYS) do
+	local keys = redis.call("SMEMBERS", tag)
+	for i = 1, #keys, tonumber(ARGV[1]) do
+		redis.call("DEL", unpack(keys, i, math.min(i + tonumber(ARGV[1]) - 1, #keys)))
+	end
+	redis.call("DEL", tag)
+end
+return 0
+`)
+
+// RedisStore keeps cache entries in Redis under a key prefix. Tags are Redis
+// sets of the keys stored with them.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a new Redis store
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get returns the value under each key, or nil for keys that aren't cached
+func (s *RedisStore) Get(ctx context.Context, keys []string) ([][]byte, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := s.client.MGet(ctx, s.keys(keys)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([][]byte, len(values))
+	for i, value := range values {
+		if value, ok := value.(string); ok {
+			data[i] = []byte(value)
+		}
+	}
+
+	return data, nil
+}
+
+// Set stores the entries with the given TTL and tags in one atomic step
+func (s *RedisStore) Set(ctx context.Context, entries []Entry, ttl time.Duration, tags []string) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(entries)+len(tags))
+	args := make([]interface{}, 0, len(entries)+2)
+	args = append(args, len(entries), ttl.Milliseconds())
+	for _, entry := range entries {
+		keys = append(keys, s.prefix+entry.Key)
+		args = append(args, entry.Value)
+	}
+	for _, tag := range tags {
+		keys = append(keys, s.prefix+tagKeyInfix+tag)
+	}
+
+	return setScript.Run(ctx, s.client, keys, args...).Err()
+}
+
+// Delete removes the entries under the keys
+func (s *RedisStore) Delete(ctx context.Context, keys []string) error {
+	return s.client.Del(ctx, s.keys(keys)...).Err()
+}
+
+// Invalidate removes every entry stored with any of the tags
+func (s *RedisStore) Invalidate(ctx context.Context, tags []string) error {
+	return invalidateScript.Run(ctx, s.client, s.tagKeys(tags), deleteBatchSize).Err()
+}
+
+// Tagged returns the keys stored with any of the tags. Keys of entries that
+// already expired may be included.
+func (s *RedisStore) Tagged(ctx context.Context, tags []string) ([]string, error) {
+	members, err := s.client.SUnion(ctx, s.tagKeys(tags)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = strings.TrimPrefix(member, s.prefix)
+	}
+
+	return keys, nil
 }
 
-// Get retrieves a value from Redis
-func Get(ctx context.Context, client *redis.Client, key string) (string, error) {
-	return client.Get(ctx, key).Result()
+// keys returns the Redis keys of entries
+func (s *RedisStore) keys(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	return prefixed
 }
 
-// Delete removes a key from Redis
-func Delete(ctx context.Context, client *redis.Client, key string) error {
-	return client.Del(ctx, key).Err()
+// tagKeys returns the Redis keys of tag sets
+func (s *RedisStore) tagKeys(tags []string) []string {
+	prefixed := make([]string, len(tags))
+	for i, tag := range tags {
+		prefixed[i] = s.prefix + tagKeyInfix + tag
+	}
+	return prefixed
 }
\ No newline at end of file
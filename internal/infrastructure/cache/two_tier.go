@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// TwoTierStore layers an in-process LRU store in front of Redis. Reads are
+// served from process memory when possible and fill it from Redis on a
+// miss; writes go to both tiers.
+//
+// Deletes and invalidations only reach the local tier of the instance that
+// makes them, so other instances may serve a stale entry for up to the local
+// TTL.
+type TwoTierStore struct {
+	local    *LRUStore
+	remote   *RedisStore
+	localTTL time.Duration
+}
+
+// NewTwoTierStore creates a new two-tier store that keeps entries in process
+// memory for at most localTTL
+func NewTwoTierStore(local *LRUStore, remote *RedisStore, localTTL time.Duration) *TwoTierStore {
+	return &TwoTierStore{
+		local:    local,
+		remote:   remote,
+		localTTL: localTTL,
+	}
+}
+
+// Get returns the value under each key, or nil for keys that aren't cached
+func (s *TwoTierStore) Get(ctx context.Context, keys []string) ([][]byte, error) {
+	data, err := s.local.Get(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	var positions []int
+	for i, value := range data {
+		if value == nil {
+			missing = append(missing, keys[i])
+			positions = append(positions, i)
+		}
+	}
+	if len(missing) == 0 {
+		return data, nil
+	}
+
+	remote, err := s.remote.Get(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill the local tier with the entries found in Redis
+	var found []Entry
+	for i, value := range remote {
+		if value != nil {
+			data[positions[i]] = value
+			found = append(found, Entry{Key: missing[i], Value: value})
+		}
+	}
+	if len(found) > 0 {
+		s.local.Set(ctx, found, s.localTTL, nil)
+	}
+
+	return data, nil
+}
+
+// Set stores the entries in Redis and then in process memory
+func (s *TwoTierStore) Set(ctx context.Context, entries []Entry, ttl time.Duration, tags []string) error {
+	if err := s.remote.Set(ctx, entries, ttl, tags); err != nil {
+		return err
+	}
+
+	localTTL := s.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	return s.local.Set(ctx, entries, localTTL, tags)
+}
+
+// Delete removes the entries from both tiers
+func (s *TwoTierStore) Delete(ctx context.Context, keys []string) error {
+	s.local.Delete(ctx, keys)
+	return s.remote.Delete(ctx, keys)
+}
+
+// Invalidate removes every entry stored with any of the tags from both
+// tiers. Local entries filled from Redis don't carry their tags, so their
+// keys are looked up in Redis first.
+func (s *TwoTierStore) Invalidate(ctx context.Context, tags []string) error {
+	keys, err := s.remote.Tagged(ctx, tags)
+	if err != nil {
+		return err
+	}
+
+	s.local.Delete(ctx, keys)
+	s.local.Invalidate(ctx, tags)
+	return s.remote.Invalidate(ctx, tags)
+}
\ No newline at end of file
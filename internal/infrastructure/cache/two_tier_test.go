@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestTwoTierStore(t *testing.T) (*TwoTierStore, *LRUStore, *RedisStore) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	local := NewLRUStore(10)
+	remote := NewRedisStore(client, "test:")
+	return NewTwoTierStore(local, remote, time.Minute), local, remote
+}
+
+func TestTwoTierStoreFillsLocalTierFromRedis(t *testing.T) {
+	store, local, remote := newTestTwoTierStore(t)
+	ctx := context.Background()
+
+	if err := remote.Set(ctx, entries("a"), time.Minute, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if got := cached(t, local, "a"); len(got) != 0 {
+		t.Fatal("the local tier holds an entry only stored in Redis")
+	}
+	if got := cached(t, store, "a", "b"); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("cached keys = %v, want [a]", got)
+	}
+	if got := cached(t, local, "a"); len(got) != 1 {
+		t.Error("a Redis hit didn't fill the local tier")
+	}
+}
+
+func TestTwoTierStoreServesLocalTier(t *testing.T) {
+	store, _, remote := newTestTwoTierStore(t)
+	ctx := context.Background()
+
+	if err := store.Set(ctx, entries("a"), time.Minute, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := cached(t, remote, "a"); len(got) != 1 {
+		t.Fatal("Set didn't reach Redis")
+	}
+
+	// Entries deleted from Redis by another instance stay in process memory
+	remote.Delete(ctx, []string{"a"})
+	if got := cached(t, store, "a"); len(got) != 1 {
+		t.Error("the local tier didn't serve its entry")
+	}
+}
+
+func TestTwoTierStoreInvalidatesFilledEntries(t *testing.T) {
+	store, local, remote := newTestTwoTierStore(t)
+	ctx := context.Background()
+
+	if err := remote.Set(ctx, entries("a", "b"), time.Minute, []string{"red"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := remote.Set(ctx, entries("c"), time.Minute, []string{"blue"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// Fill the local tier, which doesn't learn the tags
+	cached(t, store, "a", "b", "c")
+
+	if err := store.Invalidate(ctx, []string{"red"}); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+
+	if got := cached(t, local, "a", "b", "c"); len(got) != 1 || got[0] != "c" {
+		t.Errorf("local keys = %v, want [c]", got)
+	}
+	if got := cached(t, remote, "a", "b", "c"); len(got) != 1 || got[0] != "c" {
+		t.Errorf("Redis keys = %v, want [c]", got)
+	}
+}
+
+func TestTwoTierStoreCapsLocalTTL(t *testing.T) {
+	store, local, _ := newTestTwoTierStore(t)
+	ctx := context.Background()
+
+	if err := store.Set(ctx, entries("a"), time.Millisecond, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if got := cached(t, local, "a"); len(got) != 0 {
+		t.Error("the local tier kept an entry past its TTL")
+	}
+}
\ No newline at end of file
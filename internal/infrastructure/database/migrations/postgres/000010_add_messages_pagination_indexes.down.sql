@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_messages_user_id_created_at_id;
+
+DROP INDEX IF EXISTS idx_messages_created_at_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_messages_created_at_id ON messages(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_user_id_created_at_id ON messages(user_id, created_at, id);
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- The text search configuration must match SEARCH_LANGUAGE, which parses
+-- queries; a different language needs a new migration rebuilding the column.
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
\ No newline at end of file
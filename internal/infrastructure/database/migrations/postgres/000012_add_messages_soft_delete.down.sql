@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_deleted_at;
+
+DROP INDEX IF EXISTS idx_messages_user_id_deleted_at;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS deleted_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS message_revisions;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS revision_count;
\ No newline at end of file
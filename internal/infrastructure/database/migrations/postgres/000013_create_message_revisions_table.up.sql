@@ -0,0 +1,16 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS revision_count INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS message_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (message_id, revision)
+);
+
+-- Existing messages start with their current content as the first revision
+INSERT INTO message_revisions (message_id, revision, content, edited_by, created_at)
+SELECT id, 1, content, user_id, created_at FROM messages
+ON CONFLICT (message_id, revision) DO NOTHING;
\ No newline at end of file
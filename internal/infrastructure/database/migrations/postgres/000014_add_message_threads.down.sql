@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_messages_parent_id;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS reply_count;
+ALTER TABLE messages DROP COLUMN IF EXISTS parent_id;
\ No newline at end of file
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS channels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(80) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    visibility VARCHAR(16) NOT NULL DEFAULT 'public',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channels_lower_name ON channels (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS channel_members (
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (channel_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_members_user_id ON channel_members (user_id);
+
+-- Existing messages move to a default public channel that every existing
+-- user is a member of
+INSERT INTO channels (id, name, description, visibility)
+VALUES ('00000000-0000-0000-0000-000000000001', 'general', 'Messages from before channels were introduced', 'public')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO channel_members (channel_id, user_id)
+SELECT '00000000-0000-0000-0000-000000000001', id FROM users
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS channel_id UUID REFERENCES channels(id) ON DELETE CASCADE;
+UPDATE messages SET channel_id = '00000000-0000-0000-0000-000000000001' WHERE channel_id IS NULL;
+ALTER TABLE messages ALTER COLUMN channel_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_messages_channel_id_created_at_id ON messages (channel_id, created_at, id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS conversation_participants;
+DROP TABLE IF EXISTS conversation_messages;
+DROP TABLE IF EXISTS conversations;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE channel_members
+    DROP COLUMN IF EXISTS last_read_message_at,
+    DROP COLUMN IF EXISTS last_read_message_id;
\ No newline at end of file
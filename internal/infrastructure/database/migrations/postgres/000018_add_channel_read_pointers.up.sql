@@ -0,0 +1,3 @@
+ALTER TABLE channel_members
+    ADD COLUMN IF NOT EXISTS last_read_message_id UUID,
+    ADD COLUMN IF NOT EXISTS last_read_message_at TIMESTAMP WITH TIME ZONE;
\ No newline at end of file
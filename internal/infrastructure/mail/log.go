@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogSender writes emails to the logger instead of delivering them.
+// Intended for development.
+type LogSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender creates a new log-only sender
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{
+		logger: logger,
+	}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.logger.InfoContext(ctx, "Email",
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
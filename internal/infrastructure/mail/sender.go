@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/ivmello/go-api-template/internal/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender creates the sender selected by the mail driver configuration
+func NewSender(cfg config.MailConfig, logger *slog.Logger) (Sender, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPSender(cfg), nil
+	case "log":
+		return NewLogSender(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,154 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/config"
+)
+
+// receivedMail is what the SMTP stand-in received in one session
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer runs a minimal SMTP server accepting a single session on a
+// local port and returns its port and the mail it receives
+func startSMTPServer(t *testing.T) (int, <-chan receivedMail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan receivedMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var mail receivedMail
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				mail.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				mail.to = append(mail.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				tp.PrintfLine("250 OK")
+			case command == "DATA":
+				tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				mail.data = string(data)
+				tp.PrintfLine("250 OK")
+			case command == "QUIT":
+				tp.PrintfLine("221 Bye")
+				received <- mail
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	port, received := startSMTPServer(t)
+
+	sender := NewSMTPSender(config.MailConfig{
+		From:     "no-reply@example.com",
+		SMTPHost: "127.0.0.1",
+		SMTPPort: port,
+		Timeout:  5 * time.Second,
+	})
+
+	err := sender.Send(context.Background(), Message{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Réinitialisation du mot de passe",
+		Body:    "Hello,\nfollow the link to continue.",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var mail receivedMail
+	select {
+	case mail = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the SMTP server received no mail")
+	}
+
+	if mail.from != "no-reply@example.com" {
+		t.Errorf("envelope sender = %q, want no-reply@example.com", mail.from)
+	}
+	if strings.Join(mail.to, ",") != "alice@example.com,bob@example.com" {
+		t.Errorf("envelope recipients = %v", mail.to)
+	}
+
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(mail.data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("parse headers: %v", err)
+	}
+	if got := msg.Get("To"); got != "alice@example.com, bob@example.com" {
+		t.Errorf("To = %q", got)
+	}
+	if got := msg.Get("Subject"); got != "=?utf-8?q?R=C3=A9initialisation_du_mot_de_passe?=" {
+		t.Errorf("Subject = %q", got)
+	}
+	if got := msg.Get("Content-Type"); got != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	_, body, _ := strings.Cut(mail.data, "\n\n")
+	if body != "Hello,\nfollow the link to continue.\n" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestSMTPSenderUnreachable(t *testing.T) {
+	// Find a port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	sender := NewSMTPSender(config.MailConfig{
+		From:     "no-reply@example.com",
+		SMTPHost: "127.0.0.1",
+		SMTPPort: port,
+		Timeout:  time.Second,
+	})
+
+	err = sender.Send(context.Background(), Message{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"})
+	if err == nil {
+		t.Fatal("Send succeeded without a server on port " + strconv.Itoa(port))
+	}
+}
\ No newline at end of file
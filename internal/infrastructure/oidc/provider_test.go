@@ -0,0 +1,235 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ivmello/go-api-template/internal/config"
+)
+
+const (
+	testClientID     = "go-api-template"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost:8080/api/v1/auth/oidc/test/callback"
+)
+
+// authorization is a code issued by the test provider
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+// testProvider is an in-process OpenID Connect provider that signs the user
+// in without interaction and enforces PKCE on the token endpoint
+type testProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	p := &testProvider{key: key, codes: make(map[string]authorization)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *testProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(metadata{
+		Issuer:                p.server.URL,
+		AuthorizationEndpoint: p.server.URL + "/authorize",
+		TokenEndpoint:         p.server.URL + "/token",
+		JWKSURI:               p.server.URL + "/jwks",
+	})
+}
+
+// authorize issues a code for the request and redirects back to the client
+func (p *testProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != testClientID || query.Get("redirect_uri") != testRedirectURL ||
+		query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code, _ := randomString(16)
+	p.mu.Lock()
+	p.codes[code] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	p.mu.Unlock()
+
+	callback := url.Values{"code": {code}, "state": {query.Get("state")}}
+	http.Redirect(w, r, testRedirectURL+"?"+callback.Encode(), http.StatusFound)
+}
+
+// token redeems a code once its PKCE verifier matches the challenge
+func (p *testProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	r.ParseForm()
+	p.mu.Lock()
+	auth, ok := p.codes[r.Form.Get("code")]
+	delete(p.codes, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("redirect_uri") != testRedirectURL ||
+		CodeChallenge(r.Form.Get("code_verifier")) != auth.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, IDClaims{
+		Nonce:         auth.nonce,
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Name:          "Alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.server.URL,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	})
+	idToken.Header["kid"] = "test-key"
+	signed, _ := idToken.SignedString(p.key)
+
+	json.NewEncoder(w).Encode(Tokens{AccessToken: "access", IDToken: signed, TokenType: "Bearer"})
+}
+
+func (p *testProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	json.NewEncoder(w).Encode(map[string][]rawJWK{"keys": {{
+		Kty: "RSA",
+		Kid: "test-key",
+		Use: "sig",
+		N:   encode(p.key.N.Bytes()),
+		E:   encode(big.NewInt(int64(p.key.E)).Bytes()),
+	}}})
+}
+
+// login starts a login with the relying party and follows the provider's
+// redirect, returning the code and state of the callback
+func (p *testProvider) login(t *testing.T, rp *Provider, state, nonce, verifier string) (string, string) {
+	t.Helper()
+
+	authURL, err := rp.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want 302", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func newTestRelyingParty(p *testProvider) *Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:         "test",
+		IssuerURL:    p.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}, p.server.Client())
+}
+
+func TestProviderAuthorizationCodeFlow(t *testing.T) {
+	provider := newTestProvider(t)
+	rp := newTestRelyingParty(provider)
+
+	state, _ := NewState()
+	nonce, _ := NewState()
+	verifier, _ := NewCodeVerifier()
+	code, returnedState := provider.login(t, rp, state, nonce, verifier)
+	if returnedState != state {
+		t.Fatalf("callback state = %q, want %q", returnedState, state)
+	}
+
+	tokens, err := rp.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := rp.VerifyIDToken(context.Background(), tokens.IDToken, nonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+
+	if claims.Subject != "user-1" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Fatalf("claims = %+v", claims)
+	}
+}
+
+func TestProviderExchangeRejectsWrongVerifier(t *testing.T) {
+	provider := newTestProvider(t)
+	rp := newTestRelyingParty(provider)
+
+	verifier, _ := NewCodeVerifier()
+	code, _ := provider.login(t, rp, "state", "nonce", verifier)
+
+	other, _ := NewCodeVerifier()
+	if _, err := rp.Exchange(context.Background(), code, other); !errors.Is(err, ErrTokenExchange) {
+		t.Fatalf("Exchange with another verifier = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestProviderVerifyIDTokenRejectsWrongNonce(t *testing.T) {
+	provider := newTestProvider(t)
+	rp := newTestRelyingParty(provider)
+
+	verifier, _ := NewCodeVerifier()
+	code, _ := provider.login(t, rp, "state", "nonce", verifier)
+
+	tokens, err := rp.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := rp.VerifyIDToken(context.Background(), tokens.IDToken, "other-nonce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("VerifyIDToken with another nonce = %v, want ErrInvalidIDToken", err)
+	}
+}
\ No newline at end of file
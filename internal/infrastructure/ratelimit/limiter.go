@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// Rate limiting algorithms
+const (
+	AlgorithmSlidingWindow = "sliding_window"
+	AlgorithmTokenBucket   = "token_bucket"
+)
+
+const keyPrefix = "ratelimit:"
+
+// slidingWindowScript counts requests in a sorted set scored by their time
+// and admits a request while fewer than ARGV[1] were made in the last
+// ARGV[2] milliseconds. ARGV[3] is a unique member for the request. It
+// returns whether the request is allowed, the remaining requests, and the
+// milliseconds until the window is empty and until a request is allowed.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[3])
+	redis.call("PEXPIRE", KEYS[1], window)
+	return {1, limit - count - 1, window, 0}
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+local newest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+local retry = tonumber(oldest[2]) + window - now
+return {0, 0, tonumber(newest[2]) + window - now, retry}
+`)
+
+// tokenBucketScript keeps a bucket of up to ARGV[1] tokens in a hash, refilled
+// evenly so an empty bucket is full again after ARGV[2] milliseconds, and
+// admits a request when it can take a token. It returns the same values as
+// slidingWindowScript, with the time until the bucket is full.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local rate = capacity / period
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], period)
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) / rate), retry}
+`)
+
+// Result is the outcome of a rate limited request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Until the full limit is available again
+	RetryAfter time.Duration // Until a request is allowed, for denied requests
+}
+
+// Limiter admits requests against limits shared by every instance through
+// Redis. Each check runs as a single Lua script, so concurrent requests
+// can't overshoot a limit.
+type Limiter struct {
+	client *redis.Client
+	script *redis.Script
+}
+
+// NewLimiter creates a limiter using the configured algorithm
+func NewLimiter(client *redis.Client, cfg config.RateLimitConfig) (*Limiter, error) {
+	var script *redis.Script
+	switch cfg.Algorithm {
+	case AlgorithmSlidingWindow:
+		script = slidingWindowScript
+	case AlgorithmTokenBucket:
+		script = tokenBucketScript
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm %q", cfg.Algorithm)
+	}
+
+	return &Limiter{
+		client: client,
+		script: script,
+	}, nil
+}
+
+// Allow counts a request against the limit of key and reports whether it is
+// allowed
+func (l *Limiter) Allow(ctx context.Context, key string, limit config.RateLimit) (*Result, error) {
+	member := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+	values, err := l.script.Run(ctx, l.client, []string{keyPrefix + key}, limit.Requests, limit.Period.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// Policy picks the limit of each HTTP route and gRPC method
+type Policy struct {
+	fallback config.RateLimit
+	routes   map[string]config.RateLimit
+}
+
+// NewPolicy creates a policy applying the default limit to routes without a
+// limit of their own
+func NewPolicy(fallback config.RateLimit, routes map[string]config.RateLimit) *Policy {
+	return &Policy{
+		fallback: fallback,
+		routes:   routes,
+	}
+}
+
+// Limit returns the limit of a route, keyed by method and path pattern for
+// HTTP or by full method name for gRPC. ok is false when the route isn't
+// rate limited.
+func (p *Policy) Limit(route string) (limit config.RateLimit, ok bool) {
+	limit, found := p.routes[route]
+	if !found {
+		limit = p.fallback
+	}
+	return limit, limit.Requests > 0
+}
\ No newline at end of file
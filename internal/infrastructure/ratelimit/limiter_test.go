@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLimiter(t *testing.T, algorithm string) (*Limiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	server.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter, err := NewLimiter(client, config.RateLimitConfig{Algorithm: algorithm})
+	if err != nil {
+		t.Fatalf("NewLimiter: %v", err)
+	}
+	return limiter, server
+}
+
+// allow runs one request against the limit, failing the test on errors
+func allow(t *testing.T, limiter *Limiter, key string, limit config.RateLimit) *Result {
+	t.Helper()
+
+	result, err := limiter.Allow(context.Background(), key, limit)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	return result
+}
+
+func TestNewLimiterUnknownAlgorithm(t *testing.T) {
+	if _, err := NewLimiter(nil, config.RateLimitConfig{Algorithm: "leaky_bucket"}); err == nil {
+		t.Fatal("NewLimiter accepted an unknown algorithm")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limiter, server := newTestLimiter(t, AlgorithmSlidingWindow)
+	limit := config.RateLimit{Requests: 3, Period: time.Minute}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		server.SetTime(start.Add(time.Duration(i) * 10 * time.Second))
+		result := allow(t, limiter, "key", limit)
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("request %d: allowed %v, remaining %d", i, result.Allowed, result.Remaining)
+		}
+	}
+
+	// The fourth request waits for the first to leave the window
+	server.SetTime(start.Add(30 * time.Second))
+	result := allow(t, limiter, "key", limit)
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request over the limit: allowed %v, remaining %d", result.Allowed, result.Remaining)
+	}
+	if result.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %s, want 30s", result.RetryAfter)
+	}
+	if result.Reset != 50*time.Second {
+		t.Errorf("Reset = %s, want 50s", result.Reset)
+	}
+
+	// Denied requests don't take a slot
+	server.SetTime(start.Add(time.Minute))
+	if result := allow(t, limiter, "key", limit); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("request after the window moved: allowed %v, remaining %d", result.Allowed, result.Remaining)
+	}
+
+	// Other keys have their own window
+	if result := allow(t, limiter, "other", limit); !result.Allowed {
+		t.Error("a request on another key was denied")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limiter, server := newTestLimiter(t, AlgorithmTokenBucket)
+	limit := config.RateLimit{Requests: 2, Period: 10 * time.Second}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if result := allow(t, limiter, "key", limit); !result.Allowed {
+			t.Fatalf("request %d was denied", i)
+		}
+	}
+
+	result := allow(t, limiter, "key", limit)
+	if result.Allowed {
+		t.Fatal("a request on an empty bucket was allowed")
+	}
+	if result.RetryAfter != 5*time.Second {
+		t.Errorf("RetryAfter = %s, want 5s", result.RetryAfter)
+	}
+	if result.Reset != 10*time.Second {
+		t.Errorf("Reset = %s, want 10s", result.Reset)
+	}
+
+	// One token is back after half the period
+	server.SetTime(start.Add(5 * time.Second))
+	if result := allow(t, limiter, "key", limit); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("request after a refill: allowed %v, remaining %d", result.Allowed, result.Remaining)
+	}
+	if result := allow(t, limiter, "key", limit); result.Allowed {
+		t.Error("the refilled token was spent twice")
+	}
+}
+
+func TestPolicyLimit(t *testing.T) {
+	fallback := config.RateLimit{Requests: 100, Period: time.Minute}
+	policy := NewPolicy(fallback, map[string]config.RateLimit{
+		"POST /api/v1/messages":                 {Requests: 10, Period: time.Minute},
+		"/message.MessageService/CreateMessage": {Requests: 0, Period: time.Minute},
+	})
+
+	tests := []struct {
+		route string
+		want  config.RateLimit
+		ok    bool
+	}{
+		{"POST /api/v1/messages", config.RateLimit{Requests: 10, Period: time.Minute}, true},
+		{"GET /api/v1/messages", fallback, true},
+		{"/message.MessageService/CreateMessage", config.RateLimit{Period: time.Minute}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := policy.Limit(tt.route)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Limit(%q) = %v, %v, want %v, %v", tt.route, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	disabled := NewPolicy(config.RateLimit{}, nil)
+	if _, ok := disabled.Limit("GET /api/v1/messages"); ok {
+		t.Error("a zero default limit applied to a route")
+	}
+}
\ No newline at end of file
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"strings"
 
@@ -11,126 +12,152 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
-// Key for user ID in context
+// Keys for authentication data in context
 type contextKey string
-const UserIDKey contextKey = "user_id"
 
-// AuthMiddleware validates JWT tokens for HTTP requests
-func AuthMiddleware() gin.HandlerFunc {
+const (
+	UserIDKey contextKey = "user_id"
+	RolesKey  contextKey = "roles"
+	ScopesKey contextKey = "scopes"
+	ClaimsKey contextKey = "claims"
+)
+
+// TokenValidator validates access tokens, including revocation checks
+type TokenValidator interface {
+	ValidateAccessToken(ctx context.Context, token string) (*auth.Claims, error)
+}
+
+// APIKeyValidator validates API keys and returns claims restricted to the
+// key's scopes
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*auth.Claims, error)
+}
+
+// AuthMiddleware validates JWT tokens for HTTP requests. When apiKeys is not
+// nil, API keys are accepted too, either as "Authorization: ApiKey {key}" or
+// in the X-API-Key header.
+func AuthMiddleware(validator TokenValidator, apiKeys APIKeyValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		apiKeyHeader := c.GetHeader("X-API-Key")
+		if authHeader == "" && apiKeyHeader == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
 			return
 		}
 
-		// Check if header starts with Bearer
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+		// Parse credentials
+		scheme, credential, err := parseCredentials(authHeader, apiKeyHeader)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
-		// Extract token
-		tokenString := parts[1]
-
-		// Parse and validate token
-		claims, err := auth.ValidateToken(tokenString)
+		// Validate credentials
+		var claims *auth.Claims
+		switch {
+		case scheme == schemeBearer:
+			claims, err = validator.ValidateAccessToken(c.Request.Context(), credential)
+		case apiKeys != nil:
+			claims, err = apiKeys.ValidateAPIKey(c.Request.Context(), credential)
+		default:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API keys are not accepted for this endpoint"})
+			return
+		}
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			return
 		}
 
-		// Set user ID in context
+		// Set user ID, roles, scopes and claims in context
 		c.Set("user_id", claims.UserID)
+		c.Set("roles", claims.Roles)
+		c.Set("scopes", claims.Scopes)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
 
-// GRPCAuth returns a unary server interceptor for authenticating gRPC requests
-func GRPCAuth() grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		// Skip authentication for certain methods
-		if isPublicMethod(info.FullMethod) {
-			return handler(ctx, req)
+// WebSocketBearerToken lets browser WebSocket clients, which can't set
+// headers, pass their access token as a "bearer.{token}" subprotocol. The
+// token is moved to the Authorization header, so it must run before
+// AuthMiddleware.
+func WebSocketBearerToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Next()
+			return
+		}
+
+		var protocols []string
+		for _, protocol := range websocketProtocols(c.Request) {
+			if token, ok := strings.CutPrefix(protocol, "bearer."); ok {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+				continue
+			}
+			protocols = append(protocols, protocol)
 		}
 
-		// Get metadata from context
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Errorf(codes.Unauthenticated, "metadata is required")
+		// Keep the token out of the subprotocols the server may echo back
+		c.Request.Header.Del("Sec-WebSocket-Protocol")
+		if len(protocols) > 0 {
+			c.Request.Header.Set("Sec-WebSocket-Protocol", strings.Join(protocols, ", "))
 		}
+		c.Next()
+	}
+}
 
-		// Get authorization token
-		values := md.Get("authorization")
-		if len(values) == 0 {
-			return nil, status.Errorf(codes.Unauthenticated, "authorization token is required")
+// websocketProtocols returns the subprotocols offered by a WebSocket client
+func websocketProtocols(r *http.Request) []string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
 		}
+	}
+	return protocols
+}
 
-		// Check token format
-		authHeader := values[0]
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid authorization format, expected 'Bearer {token}'")
+// GRPCAuth returns a unary server interceptor for authenticating gRPC requests.
+// API keys are accepted on methods whose required permission is an API key scope.
+func GRPCAuth(validator TokenValidator, apiKeys APIKeyValidator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Skip authentication for certain methods
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
 		}
 
-		// Validate token
-		tokenString := parts[1]
-		claims, err := auth.ValidateToken(tokenString)
+		// Authenticate request
+		newCtx, err := authenticateGRPC(ctx, info.FullMethod, validator, apiKeys)
 		if err != nil {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+			return nil, err
 		}
 
-		// Add user ID to context
-		newCtx := context.WithValue(ctx, UserIDKey, claims.UserID)
-
 		// Call the handler with the new context
 		return handler(newCtx, req)
 	}
 }
 
 // GRPCStreamAuth returns a stream server interceptor for authenticating gRPC stream requests
-func GRPCStreamAuth() grpc.StreamServerInterceptor {
+func GRPCStreamAuth(validator TokenValidator, apiKeys APIKeyValidator) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		// Skip authentication for certain methods
 		if isPublicMethod(info.FullMethod) {
 			return handler(srv, ss)
 		}
 
-		// Get metadata from context
-		ctx := ss.Context()
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return status.Errorf(codes.Unauthenticated, "metadata is required")
-		}
-
-		// Get authorization token
-		values := md.Get("authorization")
-		if len(values) == 0 {
-			return status.Errorf(codes.Unauthenticated, "authorization token is required")
-		}
-
-		// Check token format
-		authHeader := values[0]
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return status.Errorf(codes.Unauthenticated, "invalid authorization format, expected 'Bearer {token}'")
-		}
-
-		// Validate token
-		tokenString := parts[1]
-		claims, err := auth.ValidateToken(tokenString)
+		// Authenticate stream
+		newCtx, err := authenticateGRPC(ss.Context(), info.FullMethod, validator, apiKeys)
 		if err != nil {
-			return status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+			return err
 		}
 
-		// Create a new context with the user ID
-		newCtx := context.WithValue(ctx, UserIDKey, claims.UserID)
-
 		// Wrap the server stream with the new context
 		wrappedStream := &wrappedServerStream{
 			ServerStream: ss,
@@ -142,6 +169,80 @@ func GRPCStreamAuth() grpc.StreamServerInterceptor {
 	}
 }
 
+// authenticateGRPC validates the bearer token or API key in the incoming
+// metadata and returns a context carrying the user ID and claims
+func authenticateGRPC(ctx context.Context, method string, validator TokenValidator, apiKeys APIKeyValidator) (context.Context, error) {
+	// Get metadata from context
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "metadata is required")
+	}
+
+	// Get authorization token or API key
+	authHeader := firstValue(md, "authorization")
+	apiKeyHeader := firstValue(md, "x-api-key")
+	if authHeader == "" && apiKeyHeader == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "authorization token is required")
+	}
+
+	// Parse credentials
+	scheme, credential, err := parseCredentials(authHeader, apiKeyHeader)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	// Validate credentials
+	var claims *auth.Claims
+	switch {
+	case scheme == schemeBearer:
+		claims, err = validator.ValidateAccessToken(ctx, credential)
+	case apiKeys != nil && isAPIKeyMethod(method):
+		claims, err = apiKeys.ValidateAPIKey(ctx, credential)
+	default:
+		return nil, status.Errorf(codes.Unauthenticated, "API keys are not accepted for this method")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+
+	// Add user ID, roles, scopes and claims to context
+	ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, RolesKey, claims.Roles)
+	ctx = context.WithValue(ctx, ScopesKey, claims.Scopes)
+	return context.WithValue(ctx, ClaimsKey, claims), nil
+}
+
+// Authorization schemes
+const (
+	schemeBearer = "Bearer"
+	schemeAPIKey = "ApiKey"
+)
+
+// parseCredentials extracts the scheme and credential from the Authorization
+// and API key headers. A separate API key header takes precedence.
+func parseCredentials(authHeader, apiKeyHeader string) (string, string, error) {
+	if apiKeyHeader != "" {
+		return schemeAPIKey, apiKeyHeader, nil
+	}
+
+	// Check header format
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || (parts[0] != schemeBearer && parts[0] != schemeAPIKey) {
+		return "", "", errors.New("authorization header format must be Bearer {token} or ApiKey {key}")
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// firstValue returns the first metadata value for a key, or an empty string
+func firstValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
 // GetUserIDFromContext extracts the user ID from the context
 func GetUserIDFromContext(ctx context.Context) (string, error) {
 	userID, ok := ctx.Value(UserIDKey).(string)
@@ -151,12 +252,55 @@ func GetUserIDFromContext(ctx context.Context) (string, error) {
 	return userID, nil
 }
 
+// GetRolesFromContext extracts the user roles from the context
+func GetRolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(RolesKey).([]string)
+	return roles
+}
+
+// GetScopesFromContext extracts the API key scopes from the context. It
+// returns nil for requests authenticated with an access token.
+func GetScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(ScopesKey).([]string)
+	return scopes
+}
+
+// GetClaimsFromContext extracts the token claims from the context
+func GetClaimsFromContext(ctx context.Context) (*auth.Claims, error) {
+	claims, ok := ctx.Value(ClaimsKey).(*auth.Claims)
+	if !ok {
+		return nil, errors.New("claims not found in context")
+	}
+	return claims, nil
+}
+
+// GetClientIPFromContext returns the IP address of the gRPC peer, or an empty
+// string if it is unknown
+func GetClientIPFromContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
 // Helper function to check if a method is public
 func isPublicMethod(method string) bool {
 	// List of methods that don't require authentication
 	publicMethods := []string{
 		"/auth.AuthService/Login",
 		"/auth.AuthService/Register",
+		"/auth.AuthService/Refresh",
+		"/auth.AuthService/ForgotPassword",
+		"/auth.AuthService/ResetPassword",
+		"/auth.AuthService/VerifyEmail",
+		"/auth.AuthService/ResendVerificationEmail",
+		"/auth.AuthService/VerifyMFA",
 	}
 
 	for _, m := range publicMethods {
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/internal/infrastructure/ratelimit"
+	"github.com/ivmello/go-api-template/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimit limits requests per route and caller. Callers are identified by
+// their API key, user ID or client IP, so it must run after AuthMiddleware
+// on protected routes. Requests are let through when Redis is unavailable.
+func RateLimit(limiter *ratelimit.Limiter, policy *ratelimit.Policy, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		limit, ok := policy.Limit(route)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		claims, _ := c.Get("claims")
+		result, err := allow(c.Request.Context(), limiter, route, callerKeys(claims, c.ClientIP()), limit)
+		if err != nil {
+			logger.Error("Failed to check rate limit", "error", err, "route", route)
+			c.Next()
+			return
+		}
+
+		// Set rate limit headers
+		for key, value := range rateLimitHeaders(result) {
+			c.Header(key, value)
+		}
+		if !result.Allowed {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GRPCRateLimit returns a unary server interceptor limiting requests per
+// method and caller. Rate limit headers are sent as response metadata. It
+// must run after GRPCAuth.
+func GRPCRateLimit(limiter *ratelimit.Limiter, policy *ratelimit.Policy, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, err := limitMethod(ctx, info.FullMethod, limiter, policy, logger)
+		if md != nil {
+			grpc.SetHeader(ctx, md)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// GRPCStreamRateLimit returns a stream server interceptor limiting new
+// streams per method and caller. It must run after GRPCStreamAuth.
+func GRPCStreamRateLimit(limiter *ratelimit.Limiter, policy *ratelimit.Policy, logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, err := limitMethod(ss.Context(), info.FullMethod, limiter, policy, logger)
+		if md != nil {
+			ss.SetHeader(md)
+		}
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// limitMethod counts a gRPC call against the limit of its method. It returns
+// the rate limit headers, and a ResourceExhausted error when the call is
+// denied.
+func limitMethod(ctx context.Context, method string, limiter *ratelimit.Limiter, policy *ratelimit.Policy, logger *slog.Logger) (metadata.MD, error) {
+	limit, ok := policy.Limit(method)
+	if !ok {
+		return nil, nil
+	}
+
+	claims, _ := GetClaimsFromContext(ctx)
+	result, err := allow(ctx, limiter, method, callerKeys(claims, GetClientIPFromContext(ctx)), limit)
+	if err != nil {
+		logger.Error("Failed to check rate limit", "error", err, "method", method)
+		return nil, nil
+	}
+
+	md := metadata.New(rateLimitHeaders(result))
+	if !result.Allowed {
+		return md, status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %s", result.RetryAfter.Round(time.Second))
+	}
+	return md, nil
+}
+
+// callerKeys identifies the caller of a request by API key, user ID or client
+// IP. Requests made with an API key count against the limits of both the key
+// and its owner, so minting more keys doesn't raise the owner's limits.
+func callerKeys(value interface{}, clientIP string) []string {
+	claims, _ := value.(*auth.Claims)
+	switch {
+	case claims == nil:
+		return []string{"ip:" + clientIP}
+	case claims.Scopes != nil:
+		return []string{"apikey:" + claims.ID, "user:" + claims.UserID}
+	default:
+		return []string{"user:" + claims.UserID}
+	}
+}
+
+// allow counts a request against the limit of a route for each caller key.
+// It stops at the first key that denies the request, and otherwise returns
+// the result with the fewest requests remaining.
+func allow(ctx context.Context, limiter *ratelimit.Limiter, route string, keys []string, limit config.RateLimit) (*ratelimit.Result, error) {
+	var result *ratelimit.Result
+	for _, key := range keys {
+		r, err := limiter.Allow(ctx, route+":"+key, limit)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil || !r.Allowed || r.Remaining < result.Remaining {
+			result = r
+		}
+		if !r.Allowed {
+			break
+		}
+	}
+	return result, nil
+}
+
+// rateLimitHeaders returns the X-RateLimit-* headers of a result, and
+// Retry-After for denied requests. Times are in whole seconds, rounded up.
+func rateLimitHeaders(result *ratelimit.Result) map[string]string {
+	headers := map[string]string{
+		"X-RateLimit-Limit":     strconv.Itoa(result.Limit),
+		"X-RateLimit-Remaining": strconv.Itoa(result.Remaining),
+		"X-RateLimit-Reset":     strconv.Itoa(seconds(result.Reset)),
+	}
+	if !result.Allowed {
+		headers["Retry-After"] = strconv.Itoa(seconds(result.RetryAfter))
+	}
+	return headers
+}
+
+// seconds rounds a duration up to whole seconds
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
\ No newline at end of file
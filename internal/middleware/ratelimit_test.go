@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ivmello/go-api-template/internal/config"
+	"github.com/ivmello/go-api-template/internal/infrastructure/ratelimit"
+	"github.com/ivmello/go-api-template/pkg/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCallerKeys(t *testing.T) {
+	session := &auth.Claims{UserID: "user-1"}
+	apiKey := &auth.Claims{UserID: "user-1", Scopes: []string{"messages:read"}}
+	apiKey.ID = "key-1"
+
+	tests := []struct {
+		name   string
+		claims *auth.Claims
+		want   []string
+	}{
+		{"anonymous", nil, []string{"ip:203.0.113.7"}},
+		{"session", session, []string{"user:user-1"}},
+		{"API key", apiKey, []string{"apikey:key-1", "user:user-1"}},
+	}
+
+	for _, tt := range tests {
+		if got := callerKeys(tt.claims, "203.0.113.7"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s caller keys = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllowChecksEveryCallerKey(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter, err := ratelimit.NewLimiter(client, config.RateLimitConfig{Algorithm: ratelimit.AlgorithmSlidingWindow})
+	if err != nil {
+		t.Fatalf("NewLimiter: %v", err)
+	}
+	limit := config.RateLimit{Requests: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	// Two keys of the same owner share the owner's limit
+	first := []string{"apikey:key-1", "user:user-1"}
+	second := []string{"apikey:key-2", "user:user-1"}
+
+	result, err := allow(ctx, limiter, "GET /x", first, limit)
+	if err != nil || !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("first request = %+v, %v", result, err)
+	}
+	result, err = allow(ctx, limiter, "GET /x", second, limit)
+	if err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("second request = %+v, %v, want 0 remaining on the owner", result, err)
+	}
+	result, err = allow(ctx, limiter, "GET /x", []string{"apikey:key-3", "user:user-1"}, limit)
+	if err != nil || result.Allowed {
+		t.Fatalf("request over the owner's limit = %+v, %v", result, err)
+	}
+
+	// Each key also has a limit of its own
+	result, err = allow(ctx, limiter, "GET /y", first, limit)
+	if err != nil || !result.Allowed {
+		t.Fatalf("request on another route = %+v, %v", result, err)
+	}
+	allow(ctx, limiter, "GET /y", first, limit)
+	result, err = allow(ctx, limiter, "GET /y", []string{"apikey:key-1", "user:user-2"}, limit)
+	if err != nil || result.Allowed {
+		t.Errorf("request over the key's limit = %+v, %v", result, err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CancelOnShutdown cancels the request context when the shutdown context is
+// done. Long-lived requests such as event streams use it to end promptly
+// instead of holding up a graceful server shutdown.
+func CancelOnShutdown(shutdown context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		stop := context.AfterFunc(shutdown, cancel)
+		defer stop()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
\ No newline at end of file
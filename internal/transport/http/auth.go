@@ -3,6 +3,8 @@ package http
 import (
 	"errors"
 	"time"
+
+	"github.com/ivmello/go-api-template/pkg/validator"
 )
 
 // RegisterRequest represents a user registration request
@@ -14,8 +16,8 @@ type RegisterRequest struct {
 
 // Validate validates the register request
 func (r *RegisterRequest) Validate() error {
-	if len(r.Email) < 5 {
-		return errors.New("email must be at least 5 characters")
+	if err := validator.ValidateEmail(r.Email); err != nil {
+		return err
 	}
 	if len(r.Password) < 6 {
 		return errors.New("password must be at least 6 characters")
@@ -43,15 +45,160 @@ func (r *LoginRequest) Validate() error {
 	return nil
 }
 
+// ResendVerificationRequest represents a request for a new verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// Validate validates the resend verification request
+func (r *ResendVerificationRequest) Validate() error {
+	if r.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
+}
+
+// ForgotPasswordRequest represents a request for a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// Validate validates the forgot password request
+func (r *ForgotPasswordRequest) Validate() error {
+	if r.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
+}
+
+// ResetPasswordRequest represents a request to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Validate validates the reset password request
+func (r *ResetPasswordRequest) Validate() error {
+	if r.Token == "" {
+		return errors.New("token is required")
+	}
+	if len(r.Password) < 6 {
+		return errors.New("password must be at least 6 characters")
+	}
+	return nil
+}
+
+// VerifyMFARequest represents the second step of a two-factor login
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// Validate validates the verify MFA request
+func (r *VerifyMFARequest) Validate() error {
+	if r.MFAToken == "" {
+		return errors.New("mfa_token is required")
+	}
+	if r.Code == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
+
+// ConfirmTOTPRequest represents a request to confirm two-factor enrollment
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// Validate validates the confirm TOTP request
+func (r *ConfirmTOTPRequest) Validate() error {
+	if r.Code == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
+
+// DisableTOTPRequest represents a request to turn off two-factor authentication
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// Validate validates the disable TOTP request
+func (r *DisableTOTPRequest) Validate() error {
+	if r.Password == "" {
+		return errors.New("password is required")
+	}
+	if r.Code == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
+
+// SetRolesRequest represents a request to replace a user's roles
+type SetRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
+// Validate validates the set roles request
+func (r *SetRolesRequest) Validate() error {
+	if len(r.Roles) == 0 {
+		return errors.New("at least one role is required")
+	}
+	return nil
+}
+
 // UserResponse represents a user response
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	TwoFactor     bool      `json:"two_factor_enabled"`
+	Name          string    `json:"name"`
+	Roles         []string  `json:"roles"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RefreshRequest represents a token refresh request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Validate validates the refresh request
+func (r *RefreshRequest) Validate() error {
+	if r.RefreshToken == "" {
+		return errors.New("refresh_token is required")
+	}
+	return nil
+}
+
+// LogoutRequest represents a logout request. The refresh token is optional;
+// when present its whole token family is revoked as well.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // TokenResponse represents a token response
 type TokenResponse struct {
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// TOTPEnrollmentResponse represents a pending TOTP secret
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse represents freshly issued two-factor recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
\ No newline at end of file
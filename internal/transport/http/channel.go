@@ -0,0 +1,107 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ivmello/go-api-template/pkg/validator"
+)
+
+// CreateChannelRequest represents a request to create a channel
+type CreateChannelRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty" enums:"public,private"`
+}
+
+// Validate validates the create channel request
+func (r *CreateChannelRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(r.Name) > 80 {
+		return errors.New("name must be at most 80 characters")
+	}
+	if len(r.Description) > 1000 {
+		return errors.New("description must be less than 1000 characters")
+	}
+	if r.Visibility != "" && r.Visibility != "public" && r.Visibility != "private" {
+		return errors.New("visibility must be public or private")
+	}
+	return nil
+}
+
+// InviteMemberRequest represents a request to add a user to a channel
+type InviteMemberRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// Validate validates the invite member request
+func (r *InviteMemberRequest) Validate() error {
+	if err := validator.ValidateUUID(r.UserID); err != nil {
+		return errors.New("user_id must be a user ID")
+	}
+	return nil
+}
+
+// ListChannelsRequest represents the query parameters of a channel listing
+type ListChannelsRequest struct {
+	Joined bool   `form:"joined"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// Validate validates the list channels request
+func (r *ListChannelsRequest) Validate() error {
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+// ListMembersRequest represents the query parameters of a member listing
+type ListMembersRequest struct {
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// Validate validates the list members request
+func (r *ListMembersRequest) Validate() error {
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+// ChannelResponse represents a channel response
+type ChannelResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility" enums:"public,private"`
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ChannelListResponse represents a page of channels
+type ChannelListResponse struct {
+	Channels   []ChannelResponse `json:"channels"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// MemberResponse represents a member of a channel
+type MemberResponse struct {
+	UserID   string    `json:"user_id"`
+	Role     string    `json:"role" enums:"owner,member"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// MemberListResponse represents a page of channel members
+type MemberListResponse struct {
+	Members    []MemberResponse `json:"members"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
\ No newline at end of file
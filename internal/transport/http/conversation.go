@@ -0,0 +1,152 @@
+package http
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ivmello/go-api-template/pkg/validator"
+)
+
+// CreateConversationRequest represents a request to start a conversation
+type CreateConversationRequest struct {
+	ParticipantIDs []string `json:"participant_ids" binding:"required"`
+	Kind           string   `json:"kind,omitempty" enums:"direct,group"`
+	Title          string   `json:"title,omitempty"`
+}
+
+// Validate validates the create conversation request
+func (r *CreateConversationRequest) Validate() error {
+	if len(r.ParticipantIDs) == 0 {
+		return errors.New("participant_ids is required")
+	}
+	if err := validateUserIDs(r.ParticipantIDs); err != nil {
+		return errors.New("participant_ids must be user IDs")
+	}
+	if r.Kind != "" && r.Kind != "direct" && r.Kind != "group" {
+		return errors.New("kind must be direct or group")
+	}
+	if utf8.RuneCountInString(r.Title) > 100 {
+		return errors.New("title must be at most 100 characters")
+	}
+	return nil
+}
+
+// AddParticipantsRequest represents a request to add users to a conversation
+type AddParticipantsRequest struct {
+	UserIDs []string `json:"user_ids" binding:"required"`
+}
+
+// Validate validates the add participants request
+func (r *AddParticipantsRequest) Validate() error {
+	if len(r.UserIDs) == 0 {
+		return errors.New("user_ids is required")
+	}
+	if err := validateUserIDs(r.UserIDs); err != nil {
+		return errors.New("user_ids must be user IDs")
+	}
+	return nil
+}
+
+// SendConversationMessageRequest represents a request to send a message to a
+// conversation
+type SendConversationMessageRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// Validate validates the send conversation message request
+func (r *SendConversationMessageRequest) Validate() error {
+	if r.Content == "" {
+		return errors.New("content is required")
+	}
+	if len(r.Content) > 1000 {
+		return errors.New("content must be less than 1000 characters")
+	}
+	return nil
+}
+
+// MarkReadRequest represents a request to mark the messages of a channel or
+// conversation as read, up to a message or a point in time
+type MarkReadRequest struct {
+	MessageID string     `json:"message_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+// Validate validates the mark read request
+func (r *MarkReadRequest) Validate() error {
+	if (r.MessageID == "") == (r.ReadAt == nil) {
+		return errors.New("exactly one of message_id and read_at is required")
+	}
+	if r.MessageID != "" {
+		if err := validator.ValidateUUID(r.MessageID); err != nil {
+			return errors.New("message_id must be a message ID")
+		}
+	}
+	return nil
+}
+
+// ListConversationsRequest represents the query parameters of a conversation
+// or conversation message listing
+type ListConversationsRequest struct {
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// Validate validates the list conversations request
+func (r *ListConversationsRequest) Validate() error {
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+// ConversationResponse represents a conversation response
+type ConversationResponse struct {
+	ID             string                `json:"id"`
+	Kind           string                `json:"kind" enums:"direct,group"`
+	Title          string                `json:"title,omitempty"`
+	CreatedBy      *string               `json:"created_by,omitempty"`
+	Participants   []ParticipantResponse `json:"participants"`
+	UnreadCount    int                   `json:"unread_count"`
+	LastActivityAt time.Time             `json:"last_activity_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// ConversationListResponse represents a page of conversations
+type ConversationListResponse struct {
+	Conversations []ConversationResponse `json:"conversations"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+}
+
+// ParticipantResponse represents a participant of a conversation
+type ParticipantResponse struct {
+	UserID            string    `json:"user_id"`
+	LastReadMessageID *string   `json:"last_read_message_id,omitempty"`
+	JoinedAt          time.Time `json:"joined_at"`
+}
+
+// ConversationMessageResponse represents a message of a conversation
+type ConversationMessageResponse struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	UserID         string    `json:"user_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ConversationMessageListResponse represents a page of conversation messages
+type ConversationMessageListResponse struct {
+	Messages   []ConversationMessageResponse `json:"messages"`
+	NextCursor string                        `json:"next_cursor,omitempty"`
+}
+
+// validateUserIDs returns an error unless every ID is a UUID
+func validateUserIDs(ids []string) error {
+	for _, id := range ids {
+		if err := validator.ValidateUUID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -2,22 +2,35 @@ package http
 
 import (
 	"errors"
+	"strings"
 	"time"
+
+	"github.com/ivmello/go-api-template/pkg/validator"
 )
 
 // CreateMessageRequest represents a request to create a message
 type CreateMessageRequest struct {
-	Content string `json:"content" binding:"required"`
+	ChannelID string `json:"channel_id" binding:"required"`
+	Content   string `json:"content" binding:"required"`
+	ParentID  string `json:"parent_id,omitempty"`
 }
 
 // Validate validates the create message request
 func (r *CreateMessageRequest) Validate() error {
+	if err := validator.ValidateUUID(r.ChannelID); err != nil {
+		return errors.New("channel_id must be a channel ID")
+	}
 	if r.Content == "" {
 		return errors.New("content is required")
 	}
 	if len(r.Content) > 1000 {
 		return errors.New("content must be less than 1000 characters")
 	}
+	if r.ParentID != "" {
+		if err := validator.ValidateUUID(r.ParentID); err != nil {
+			return errors.New("parent_id must be a message ID")
+		}
+	}
 	return nil
 }
 
@@ -39,9 +52,248 @@ func (r *UpdateMessageRequest) Validate() error {
 
 // MessageResponse represents a message response
 type MessageResponse struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
+	ID            string     `json:"id"`
+	ChannelID     string     `json:"channel_id"`
+	UserID        string     `json:"user_id"`
+	ParentID      *string    `json:"parent_id,omitempty"`
+	Content       string     `json:"content"`
+	Edited        bool       `json:"edited"`
+	RevisionCount int        `json:"revision_count"`
+	ReplyCount    int        `json:"reply_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+
+	Reactions   []ReactionCountResponse `json:"reactions"`
+	MyReactions []string                `json:"my_reactions"`
+}
+
+// ReactionCountResponse represents the number of users who reacted to a
+// message with an emoji
+type ReactionCountResponse struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// GetThreadRequest represents the query parameters of a thread fetch
+type GetThreadRequest struct {
+	Format string `form:"format"`
+}
+
+// Validate validates the get thread request
+func (r *GetThreadRequest) Validate() error {
+	if r.Format != "" && r.Format != "tree" && r.Format != "flat" {
+		return errors.New("format must be tree or flat")
+	}
+	return nil
+}
+
+// ThreadNodeResponse represents a message within a thread. Replies are only
+// set in the tree format.
+type ThreadNodeResponse struct {
+	Message MessageResponse      `json:"message"`
+	Depth   int                  `json:"depth"`
+	Replies []ThreadNodeResponse `json:"replies,omitempty"`
+}
+
+// ThreadResponse represents a thread as a tree under root, or as a flat list
+// of messages in reading order
+type ThreadResponse struct {
+	Root      *ThreadNodeResponse  `json:"root,omitempty"`
+	Messages  []ThreadNodeResponse `json:"messages,omitempty"`
+	Truncated bool                 `json:"truncated"`
+}
+
+// MessageEventResponse represents a change to a message sent on the event
+// stream. Deleted messages carry no content.
+type MessageEventResponse struct {
+	Type       string          `json:"type" enums:"created,updated,deleted,restored"`
+	Message    MessageResponse `json:"message"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Message WebSocket command types
+const (
+	SocketSubscribe   = "subscribe"
+	SocketUnsubscribe = "unsubscribe"
+	SocketCreate      = "create"
+	SocketUpdate      = "update"
+	SocketDelete      = "delete"
+)
+
+// SocketCommand represents a command sent by a client over the message
+// WebSocket. The ID is chosen by the client and echoed in the reply.
+type SocketCommand struct {
+	ID          string `json:"id,omitempty"`
+	Type        string `json:"type" enums:"subscribe,unsubscribe,create,update,delete"`
+	MessageID   string `json:"message_id,omitempty"`
+	ChannelID   string `json:"channel_id,omitempty"`
+	Content     string `json:"content,omitempty"`
+	ParentID    string `json:"parent_id,omitempty"`
+	LastEventID string `json:"last_event_id,omitempty"`
+}
+
+// Validate validates the socket command
+func (r *SocketCommand) Validate() error {
+	switch r.Type {
+	case SocketSubscribe, SocketUnsubscribe:
+		return nil
+	case SocketCreate:
+		create := CreateMessageRequest{ChannelID: r.ChannelID, Content: r.Content, ParentID: r.ParentID}
+		return create.Validate()
+	case SocketUpdate:
+		if r.MessageID == "" {
+			return errors.New("message_id is required")
+		}
+		update := UpdateMessageRequest{Content: r.Content}
+		return update.Validate()
+	case SocketDelete:
+		if r.MessageID == "" {
+			return errors.New("message_id is required")
+		}
+		return nil
+	}
+	return errors.New("type must be subscribe, unsubscribe, create, update or delete")
+}
+
+// Message WebSocket frame types
+const (
+	SocketOK    = "ok"
+	SocketError = "error"
+	SocketEvent = "event"
+)
+
+// SocketFrame represents a frame sent by the server over the message
+// WebSocket: the reply to a command, or a message event for subscribed
+// connections
+type SocketFrame struct {
+	Type    string                `json:"type" enums:"ok,error,event"`
+	ID      string                `json:"id,omitempty"`
+	Status  int                   `json:"status,omitempty"`
+	Error   string                `json:"error,omitempty"`
+	Message *MessageResponse      `json:"message,omitempty"`
+	EventID string                `json:"event_id,omitempty"`
+	Event   *MessageEventResponse `json:"event,omitempty"`
+}
+
+// ListMessagesRequest represents the query parameters of a message listing
+type ListMessagesRequest struct {
+	ChannelID     string     `form:"channel_id"`
+	UserID        string     `form:"user_id"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Order         string     `form:"order"`
+	Limit         int        `form:"limit"`
+	Cursor        string     `form:"cursor"`
+}
+
+// Validate validates the list messages request
+func (r *ListMessagesRequest) Validate() error {
+	if err := validator.ValidateUUID(r.ChannelID); err != nil {
+		return errors.New("channel_id must be a channel ID")
+	}
+	if r.UserID != "" {
+		if err := validator.ValidateUUID(r.UserID); err != nil {
+			return errors.New("user_id must be a user ID")
+		}
+	}
+	if r.Order != "" && r.Order != "asc" && r.Order != "desc" {
+		return errors.New("order must be asc or desc")
+	}
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	if r.CreatedAfter != nil && r.CreatedBefore != nil && !r.CreatedAfter.Before(*r.CreatedBefore) {
+		return errors.New("created_after must be before created_before")
+	}
+	return nil
+}
+
+// MessageListResponse represents a page of messages
+type MessageListResponse struct {
+	Messages   []MessageResponse `json:"messages"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// SearchMessagesRequest represents the query parameters of a message search
+type SearchMessagesRequest struct {
+	Query  string `form:"q"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// Validate validates the search messages request
+func (r *SearchMessagesRequest) Validate() error {
+	if strings.TrimSpace(r.Query) == "" {
+		return errors.New("q is required")
+	}
+	if len(r.Query) > 256 {
+		return errors.New("q must be at most 256 characters")
+	}
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+// MessageSearchResult represents a message matching a search
+type MessageSearchResult struct {
+	Message MessageResponse `json:"message"`
+	Rank    float32         `json:"rank"`
+	Snippet string          `json:"snippet"`
+}
+
+// MessageSearchResponse represents a page of search results
+type MessageSearchResponse struct {
+	Results    []MessageSearchResult `json:"results"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// ListTrashRequest represents the query parameters of a trash listing
+type ListTrashRequest struct {
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// Validate validates the list trash request
+func (r *ListTrashRequest) Validate() error {
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+// RevisionResponse represents a revision of a message
+type RevisionResponse struct {
+	Revision  int       `json:"revision"`
 	Content   string    `json:"content"`
+	EditedBy  *string   `json:"edited_by,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DiffRevisionsRequest represents the query parameters of a revision diff
+type DiffRevisionsRequest struct {
+	From int `form:"from"`
+	To   int `form:"to"`
+}
+
+// Validate validates the diff revisions request
+func (r *DiffRevisionsRequest) Validate() error {
+	if r.From < 1 || r.To < 1 {
+		return errors.New("from and to must be revision numbers")
+	}
+	return nil
+}
+
+// DiffLineResponse represents one line of a diff
+type DiffLineResponse struct {
+	Op   string `json:"op" enums:"equal,insert,delete"`
+	Text string `json:"text"`
+}
+
+// RevisionDiffResponse represents the line-level difference between two revisions
+type RevisionDiffResponse struct {
+	From  RevisionResponse   `json:"from"`
+	To    RevisionResponse   `json:"to"`
+	Lines []DiffLineResponse `json:"lines"`
 }
\ No newline at end of file
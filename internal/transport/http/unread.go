@@ -0,0 +1,15 @@
+package http
+
+// UnreadResponse represents the current user's unread messages
+type UnreadResponse struct {
+	Total         int                   `json:"total"`
+	Channels      []UnreadCountResponse `json:"channels"`
+	Conversations []UnreadCountResponse `json:"conversations"`
+}
+
+// UnreadCountResponse represents the unread messages in one channel or
+// conversation
+type UnreadCountResponse struct {
+	ID     string `json:"id"`
+	Unread int    `json:"unread"`
+}
\ No newline at end of file
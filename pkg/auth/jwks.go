@@ -0,0 +1,22 @@
+package auth
+
+// JWK is a public JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid"`
+
+	// RSA public key members
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP (Ed25519) public key members
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is a set of public JSON Web Keys, as served from /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,43 +9,83 @@ import (
 
 // Claims represents the JWT claims
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID        string   `json:"user_id"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles,omitempty"`
+	Purpose       string   `json:"purpose,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"` // Set for API keys only
 	jwt.RegisteredClaims
 }
 
+// PurposeMFAChallenge marks a token that only proves the password step of a
+// two-factor login. Access tokens carry no purpose.
+const PurposeMFAChallenge = "mfa_challenge"
+
 var (
 	ErrInvalidToken = errors.New("invalid token")
 	ErrExpiredToken = errors.New("token has expired")
 )
 
-// GenerateToken creates a new JWT token
-func GenerateToken(userID, email string, expirationHours int) (string, error) {
-	// Set expiration time
-	expirationTime := time.Now().Add(time.Duration(expirationHours) * time.Hour)
-
-	// Create claims
-	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+func init() {
+	// Issue times carry milliseconds so tokens issued in the same second as
+	// a "log out all sessions" can be told apart from the ones before it
+	jwt.TimePrecision = time.Millisecond
+}
+
+// KeyManager signs tokens with its active key and verifies them with any
+// known key, so retired keys keep validating tokens issued before a rotation
+type KeyManager struct {
+	signingKey  *Key
+	retiredKeys []*Key
+	keys        map[string]*Key
+}
+
+// NewKeyManager creates a key manager that signs with the given key and
+// additionally accepts tokens signed by any of the retired keys
+func NewKeyManager(signingKey *Key, retiredKeys ...*Key) (*KeyManager, error) {
+	if signingKey.signKey == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	keys := map[string]*Key{signingKey.ID: signingKey}
+	for _, key := range retiredKeys {
+		keys[key.ID] = key
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return &KeyManager{
+		signingKey:  signingKey,
+		retiredKeys: retiredKeys,
+		keys:        keys,
+	}, nil
+}
 
-	// Get secret key from environment variable
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "default-secret-key-for-development-only"
+// GenerateToken creates a new JWT token from the custom claims. The registered
+// claims (ID, expiry, issue time) are filled in by the key manager.
+func (m *KeyManager) GenerateToken(claims Claims, ttl time.Duration) (string, error) {
+	// Set expiration time
+	expirationTime := time.Now().Add(ttl)
+
+	// Generate a unique token ID so the token can be revoked individually
+	tokenID, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", err
 	}
 
+	// Set registered claims
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		ExpiresAt: jwt.NewNumericDate(expirationTime),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+	}
+
+	// Create token stamped with the signing key ID
+	token := jwt.NewWithClaims(m.signingKey.Method, &claims)
+	token.Header["kid"] = m.signingKey.ID
+
 	// Sign token
-	tokenString, err := token.SignedString([]byte(secretKey))
+	tokenString, err := token.SignedString(m.signingKey.signKey)
 	if err != nil {
 		return "", err
 	}
@@ -55,18 +94,10 @@ func GenerateToken(userID, email string, expirationHours int) (string, error) {
 }
 
 // ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString string) (*Claims, error) {
-	// Get secret key from environment variable
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "default-secret-key-for-development-only"
-	}
-
+func (m *KeyManager) ValidateToken(tokenString string) (*Claims, error) {
 	// Parse token
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, m.keyFunc)
 
 	// Check for parsing errors
 	if err != nil {
@@ -76,10 +107,45 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		return nil, err
 	}
 
-	// Check if token is valid
-	if !token.Valid {
+	// Check if token is valid. Every token is issued with an ID, which is
+	// what individual revocation is keyed on.
+	if !token.Valid || claims.ID == "" {
 		return nil, ErrInvalidToken
 	}
 
 	return claims, nil
+}
+
+// JWKS returns the public keys used to verify tokens. Symmetric keys are
+// never published.
+func (m *KeyManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	// Active key first, then retired keys
+	for _, key := range append([]*Key{m.signingKey}, m.retiredKeys...) {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+// keyFunc selects the verification key from the token's kid header
+func (m *KeyManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := m.signingKey
+
+	// Tokens without a key ID predate key rotation and were signed with the active key
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = m.keys[kid]; !ok {
+			return nil, ErrUnknownKeyID
+		}
+	}
+
+	// Reject algorithm substitution
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.verifyKey, nil
 }
\ No newline at end of file
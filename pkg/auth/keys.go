@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type, expected RSA or Ed25519")
+	ErrUnknownKeyID   = errors.New("unknown key ID")
+	ErrNoSigningKey   = errors.New("key cannot be used for signing")
+)
+
+// Key is a signing or verification key identified by a key ID
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	// signKey is nil for verification-only keys
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey creates a symmetric HS256 key from a shared secret
+func NewHMACKey(id, secret string) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// LoadPrivateKeyFile loads an RSA or Ed25519 private key from a PEM file.
+// The key ID is the RFC 7638 thumbprint of the public key.
+func LoadPrivateKeyFile(path string) (*Key, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse private key
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unexpected PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	key, err := newAsymmetricKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.signKey = signer
+
+	return key, nil
+}
+
+// LoadPublicKeyFile loads an RSA or Ed25519 verification key from a PEM file.
+// Private key files are accepted too; only their public half is kept.
+func LoadPublicKeyFile(path string) (*Key, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to deriving the public key from a private key
+	if block.Type != "PUBLIC KEY" {
+		key, err := LoadPrivateKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key.signKey = nil
+		return key, nil
+	}
+
+	// Parse public key
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return newAsymmetricKey(parsed)
+}
+
+// newAsymmetricKey creates a verification key for an RSA or Ed25519 public key
+func newAsymmetricKey(public crypto.PublicKey) (*Key, error) {
+	key := &Key{verifyKey: public}
+
+	switch public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	// Derive the key ID from the key material
+	thumbprint, err := key.thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = thumbprint
+
+	return key, nil
+}
+
+// JWK returns the public JSON Web Key representation of the key.
+// Symmetric keys have no public representation and return false.
+func (k *Key) JWK() (JWK, bool) {
+	switch public := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Kid: k.ID,
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Kid: k.ID,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// thumbprint computes the RFC 7638 JWK thumbprint of the key
+func (k *Key) thumbprint() (string, error) {
+	jwk, ok := k.JWK()
+	if !ok {
+		return "", ErrUnsupportedKey
+	}
+
+	// Only the required members, in lexicographic order
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// readPEMFile reads the first PEM block of a file
+func readPEMFile(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	return block, nil
+}
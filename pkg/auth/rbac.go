@@ -0,0 +1,98 @@
+package auth
+
+// Roles that can be assigned to users
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleUser      = "user"
+)
+
+// Permission is an action a role may perform
+type Permission string
+
+// Permissions granted through roles
+const (
+	PermMessagesRead     Permission = "messages:read"
+	PermMessagesWrite    Permission = "messages:write"
+	PermMessagesModerate Permission = "messages:moderate"
+	PermUsersManage      Permission = "users:manage"
+)
+
+// rolePermissions maps each role to the permissions it grants
+var rolePermissions = map[string][]Permission{
+	RoleUser: {
+		PermMessagesRead,
+		PermMessagesWrite,
+	},
+	RoleModerator: {
+		PermMessagesRead,
+		PermMessagesWrite,
+		PermMessagesModerate,
+	},
+	RoleAdmin: {
+		PermMessagesRead,
+		PermMessagesWrite,
+		PermMessagesModerate,
+		PermUsersManage,
+	},
+}
+
+// IsValidRole reports whether the role is known
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasRole reports whether any of the roles is one of the wanted roles
+func HasRole(roles []string, wanted ...string) bool {
+	for _, role := range roles {
+		for _, w := range wanted {
+			if role == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether any of the roles grants the permission
+func HasPermission(roles []string, permission Permission) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apiKeyScopes lists the permissions an API key can be scoped to
+var apiKeyScopes = []Permission{
+	PermMessagesRead,
+	PermMessagesWrite,
+}
+
+// IsAPIKeyScope reports whether an API key can be granted the permission
+func IsAPIKeyScope(permission Permission) bool {
+	for _, scope := range apiKeyScopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the scopes include the permission. Nil scopes,
+// as on access tokens, are unrestricted and only roles apply.
+func HasScope(scopes []string, permission Permission) bool {
+	if scopes == nil {
+		return true
+	}
+	for _, scope := range scopes {
+		if Permission(scope) == permission {
+			return true
+		}
+	}
+	return false
+}
\ No newline at end of file
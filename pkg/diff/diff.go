@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Op is the kind of change a line represents
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Line is a single line of a diff
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns the shortest line-level edit script turning a into b, using
+// the longest common subsequence of their lines. Deleted lines come before
+// inserted lines within each changed block.
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// Walk the table from the start to build the edit script
+	lines := make([]Line, 0, max(len(x), len(y)))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Op: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Op: Delete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Op: Insert, Text: y[j]})
+	}
+
+	return lines
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
\ No newline at end of file
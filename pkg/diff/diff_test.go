@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"both empty", "", "", []Line{}},
+		{"added text", "", "a\nb", []Line{{Insert, "a"}, {Insert, "b"}}},
+		{"removed text", "a\nb\n", "", []Line{{Delete, "a"}, {Delete, "b"}}},
+		{"unchanged", "a\nb", "a\nb", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{"trailing newline ignored", "a\nb", "a\nb\n", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{
+			"replaced line",
+			"a\nb\nc", "a\nx\nc",
+			[]Line{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}},
+		},
+		{
+			"shifted lines",
+			"a\nb\nc", "b\nc\nd",
+			[]Line{{Delete, "a"}, {Equal, "b"}, {Equal, "c"}, {Insert, "d"}},
+		},
+		{
+			"deletes before inserts in a block",
+			"a\nb\nc\nd", "a\nx\ny\nd",
+			[]Line{{Equal, "a"}, {Delete, "b"}, {Delete, "c"}, {Insert, "x"}, {Insert, "y"}, {Equal, "d"}},
+		},
+		{"blank lines kept", "a\n\nb", "a\nb", []Line{{Equal, "a"}, {Delete, ""}, {Equal, "b"}}},
+	}
+
+	for _, tt := range tests {
+		got := Lines(tt.a, tt.b)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Lines(%q, %q) = %v, want %v", tt.name, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLinesIsMinimal(t *testing.T) {
+	pairs := [][2]string{
+		{"a\nb\nc\nd\ne", "a\nc\nd\nb\ne"},
+		{"x\ny\nz", "z\ny\nx"},
+		{"one\ntwo\nthree\nfour", "zero\none\nthree\nfour\nfive"},
+	}
+	// Length of the longest common subsequence of each pair
+	common := []int{4, 1, 3}
+
+	for n, pair := range pairs {
+		var a, b []string
+		equal := 0
+		for _, line := range Lines(pair[0], pair[1]) {
+			switch line.Op {
+			case Equal:
+				a, b = append(a, line.Text), append(b, line.Text)
+				equal++
+			case Delete:
+				a = append(a, line.Text)
+			case Insert:
+				b = append(b, line.Text)
+			}
+		}
+
+		if strings.Join(a, "\n") != pair[0] || strings.Join(b, "\n") != pair[1] {
+			t.Errorf("script for %q -> %q rebuilds %q -> %q", pair[0], pair[1], a, b)
+		}
+		if equal != common[n] {
+			t.Errorf("script for %q -> %q keeps %d lines, want %d", pair[0], pair[1], equal, common[n])
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,98 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of RFC 6238 appendix B, "12345678901234567890",
+// in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8-digit codes; 6-digit codes are their last six digits
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCode(t *testing.T) {
+	for _, tt := range rfcVectors {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	got, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", 1)
+	if err != nil || got != "287082" {
+		t.Errorf("Code = %s, %v, want 287082", got, err)
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err != ErrInvalidSecret {
+		t.Errorf("Code = %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tt := range rfcVectors {
+		step, ok := Validate(rfcSecret, tt.code, time.Unix(tt.unix, 0), 0)
+		if !ok || step != Step(time.Unix(tt.unix, 0)) {
+			t.Errorf("Validate at %d = %d, %v", tt.unix, step, ok)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	// "081804" belongs to step 37037036, which spans 1111111080-1111111109
+	const code = "081804"
+	const step = 37037036
+
+	tests := []struct {
+		name string
+		unix int64
+		skew int
+		ok   bool
+	}{
+		{"first second of the step", 1111111080, 0, true},
+		{"last second of the step", 1111111109, 0, true},
+		{"one step later without skew", 1111111110, 0, false},
+		{"one step earlier without skew", 1111111079, 0, false},
+		{"one step later", 1111111139, 1, true},
+		{"one step earlier", 1111111050, 1, true},
+		{"two steps later", 1111111140, 1, false},
+		{"two steps earlier", 1111111049, 1, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Validate(rfcSecret, code, time.Unix(tt.unix, 0), tt.skew)
+		if ok != tt.ok {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, ok, tt.ok)
+		}
+		if ok && got != step {
+			t.Errorf("%s: matched step %d, want %d", tt.name, got, step)
+		}
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate accepted %q", code)
+		}
+	}
+}
\ No newline at end of file